@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/stretchr/testify/require"
+)
+
+// batchHistoryCheckTimeout bounds the end-of-test batch history check, which walks every state index.
+const batchHistoryCheckTimeout = 5 * time.Minute
+
+// BatchHistoryReport is the result of walking every state update a rollapp submitted to the hub.
+type BatchHistoryReport struct {
+	RollappID     string
+	DisputePeriod uint64
+	// HubHeight is the hub height queried right before the state updates were read
+	HubHeight  uint64
+	States     []StateUpdate
+	Violations []string
+}
+
+// Err returns an error listing every violation found, or nil if the history is consistent.
+func (r *BatchHistoryReport) Err() error {
+	if len(r.Violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("batch history of rollapp %s has %d violation(s):\n%s",
+		r.RollappID, len(r.Violations), strings.Join(r.Violations, "\n"))
+}
+
+func (r *BatchHistoryReport) violation(index uint64, format string, args ...any) {
+	r.Violations = append(r.Violations, fmt.Sprintf("state index %d: ", index)+fmt.Sprintf(format, args...))
+}
+
+// AnalyzeBatchHistory reads every state update of the rollapp from the hub and checks that:
+//   - each batch starts right after the previous one, StartHeight[i] == StartHeight[i-1] + NumBlocks[i-1]
+//   - the block descriptors of a batch cover exactly its heights, without gaps or overlaps
+//   - creation heights never decrease
+//   - a batch is finalized exactly dispute_period_in_blocks after its creation, and not pending past that point
+//
+// Reverted batches are only checked for their block descriptors, since a fraud proposal cuts the history short.
+// Query failures are returned as an error, violations are collected in the report.
+func AnalyzeBatchHistory(ctx context.Context, hub *dym_hub.DymHub, rollappID string) (*BatchHistoryReport, error) {
+	watcher := NewStateWatcher(hub, rollappID)
+
+	disputePeriod, err := watcher.DisputePeriod(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hubHeight, err := hub.Height(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hub height: %w", err)
+	}
+	latestIndex, err := watcher.LatestIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BatchHistoryReport{
+		RollappID:     rollappID,
+		DisputePeriod: disputePeriod,
+		HubHeight:     hubHeight,
+	}
+	for index := uint64(1); index <= latestIndex; index++ {
+		state, err := watcher.StateInfo(ctx, index)
+		if err != nil {
+			return nil, err
+		}
+		report.States = append(report.States, *state)
+	}
+
+	for i, state := range report.States {
+		checkBlockDescriptors(report, state)
+
+		if i > 0 {
+			prev := report.States[i-1]
+			if prev.Status != stateStatusReverted && state.Status != stateStatusReverted &&
+				state.StartHeight != prev.StartHeight+prev.NumBlocks {
+				report.violation(state.Index, "start height %d does not follow state index %d (start height %d, %d blocks), expected %d",
+					state.StartHeight, prev.Index, prev.StartHeight, prev.NumBlocks, prev.StartHeight+prev.NumBlocks)
+			}
+			if state.CreationHeight < prev.CreationHeight {
+				report.violation(state.Index, "creation height %d is lower than creation height %d of state index %d",
+					state.CreationHeight, prev.CreationHeight, prev.Index)
+			}
+		}
+
+		finalizationHeight := state.CreationHeight + disputePeriod
+		switch state.Status {
+		case stateStatusPending:
+			// state updates are read after hubHeight, so the finalization of this one is overdue
+			if finalizationHeight <= hubHeight {
+				report.violation(state.Index, "still %s at hub height %d, expected to finalize at hub height %d",
+					state.Status, hubHeight, finalizationHeight)
+			}
+		case stateStatusFinalized:
+			finalized, err := isFinalizedAtHeight(ctx, hub, state, finalizationHeight)
+			if err != nil {
+				return nil, err
+			}
+			if !finalized {
+				report.violation(state.Index, "no finalization event at hub height %d (created at hub height %d, dispute period %d)",
+					finalizationHeight, state.CreationHeight, disputePeriod)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func checkBlockDescriptors(report *BatchHistoryReport, state StateUpdate) {
+	if uint64(len(state.BlockDescriptors)) != state.NumBlocks {
+		report.violation(state.Index, "has %d block descriptors for %d blocks", len(state.BlockDescriptors), state.NumBlocks)
+	}
+	for i, bd := range state.BlockDescriptors {
+		height, err := strconv.ParseUint(bd.Height, 10, 64)
+		if err != nil {
+			report.violation(state.Index, "invalid block descriptor height %q", bd.Height)
+			continue
+		}
+		if expected := state.StartHeight + uint64(i); height != expected {
+			report.violation(state.Index, "block descriptor %d has height %d, expected %d", i, height, expected)
+		}
+	}
+}
+
+// isFinalizedAtHeight looks for the state_update event the hub emits when it finalizes the state update.
+func isFinalizedAtHeight(ctx context.Context, hub *dym_hub.DymHub, state StateUpdate, hubHeight uint64) (bool, error) {
+	txs, err := hub.FindTxs(ctx, hubHeight)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch hub events at height %d: %w", hubHeight, err)
+	}
+	for _, tx := range txs {
+		for _, event := range tx.Events {
+			if event.Type != "state_update" {
+				continue
+			}
+			attrs := make(map[string]string, len(event.Attributes))
+			for _, attr := range event.Attributes {
+				attrs[attr.Key] = attr.Value
+			}
+			if attrs["rollapp_id"] == state.RollappID &&
+				attrs["state_info_index"] == fmt.Sprint(state.Index) &&
+				attrs["status"] == stateStatusFinalized {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// CheckBatchHistoryOnCleanup registers an end-of-test invariant verifying the batch history of each rollapp.
+// Register it after the chains are built so it runs before their containers are removed.
+func CheckBatchHistoryOnCleanup(t *testing.T, ctx context.Context, hub *dym_hub.DymHub, rollappIDs ...string) {
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(ctx, batchHistoryCheckTimeout)
+		defer cancel()
+
+		for _, rollappID := range rollappIDs {
+			report, err := AnalyzeBatchHistory(ctx, hub, rollappID)
+			require.NoError(t, err)
+			require.NoError(t, report.Err())
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/docker/docker/client"
+)
+
+// The upgrade scenarios run against the release pairs set by these variables. The old version defaults to the
+// version of the other tests, the new version and the upgrade name have no default.
+const (
+	hubUpgradeFromEnv = "DYMENSION_UPGRADE_FROM"
+	hubUpgradeToEnv   = "DYMENSION_UPGRADE_TO"
+	hubUpgradeNameEnv = "DYMENSION_UPGRADE_NAME"
+
+	rollappEVMUpgradeFromEnv = "ROLLAPP_EVM_UPGRADE_FROM"
+	rollappEVMUpgradeToEnv   = "ROLLAPP_EVM_UPGRADE_TO"
+	rollappEVMUpgradeNameEnv = "ROLLAPP_EVM_UPGRADE_NAME"
+
+	rollappWasmUpgradeFromEnv = "ROLLAPP_WASM_UPGRADE_FROM"
+	rollappWasmUpgradeToEnv   = "ROLLAPP_WASM_UPGRADE_TO"
+	rollappWasmUpgradeNameEnv = "ROLLAPP_WASM_UPGRADE_NAME"
+
+	// chainHaltStall is how long a chain has to stay at the upgrade height to count as halted
+	chainHaltStall = 15 * time.Second
+)
+
+// ChainUpgrade is a software upgrade of a chain from one release to another.
+type ChainUpgrade struct {
+	// Name is the name of the upgrade handler of the new release
+	Name        string
+	Image       ibc.DockerImage
+	FromVersion string
+	ToVersion   string
+	// ToEnv and NameEnv are the variables setting the upgrade, for the skip message of the scenarios
+	ToEnv   string
+	NameEnv string
+}
+
+func (u ChainUpgrade) String() string {
+	return fmt.Sprintf("%s (%s:%s -> %s:%s)", u.Name, u.Image.Repository, u.FromVersion, u.Image.Repository, u.ToVersion)
+}
+
+// FromImage is the image to start the chain with.
+func (u ChainUpgrade) FromImage() ibc.DockerImage {
+	image := u.Image
+	image.Version = u.FromVersion
+	return image
+}
+
+// GetHubUpgrade returns the hub upgrade set by the environment, or false if no upgrade is set.
+func GetHubUpgrade() (ChainUpgrade, bool) {
+	return getChainUpgrade(dymensionImage, hubUpgradeFromEnv, hubUpgradeToEnv, hubUpgradeNameEnv)
+}
+
+// GetRollappUpgrade returns the upgrade of the rollapp binary set by the environment, or false if no upgrade is set.
+func GetRollappUpgrade(kind RollappKind) (ChainUpgrade, bool) {
+	switch kind {
+	case RollappEVM:
+		return getChainUpgrade(rollappEVMImage, rollappEVMUpgradeFromEnv, rollappEVMUpgradeToEnv, rollappEVMUpgradeNameEnv)
+	case RollappWasm:
+		return getChainUpgrade(rollappWasmImage, rollappWasmUpgradeFromEnv, rollappWasmUpgradeToEnv, rollappWasmUpgradeNameEnv)
+	default:
+		return ChainUpgrade{}, false
+	}
+}
+
+func getChainUpgrade(image ibc.DockerImage, fromEnv, toEnv, nameEnv string) (ChainUpgrade, bool) {
+	upgrade := ChainUpgrade{
+		Image:       image,
+		FromVersion: image.Version,
+		ToEnv:       toEnv,
+		NameEnv:     nameEnv,
+	}
+	if from, found := os.LookupEnv(fromEnv); found {
+		upgrade.FromVersion = from
+	}
+
+	var foundTo, foundName bool
+	upgrade.ToVersion, foundTo = os.LookupEnv(toEnv)
+	upgrade.Name, foundName = os.LookupEnv(nameEnv)
+	return upgrade, foundTo && foundName
+}
+
+// SubmitUpgrade submits the software upgrade proposal for the given halt height and votes it through.
+func SubmitUpgrade(ctx context.Context, chain *cosmos.CosmosChain, keyName string, upgrade ChainUpgrade, haltHeight uint64) error {
+	height, err := chain.Height(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get %s height: %w", chain.Config().ChainID, err)
+	}
+	if haltHeight <= height {
+		return fmt.Errorf("halt height %d is not above the %s height %d", haltHeight, chain.Config().ChainID, height)
+	}
+
+	propTx, err := chain.UpgradeLegacyProposal(ctx, keyName, cosmos.SoftwareUpgradeProposal{
+		Deposit:     "500000000000" + chain.Config().Denom, // greater than min deposit
+		Title:       "Upgrade to " + upgrade.ToVersion,
+		Name:        upgrade.Name,
+		Description: "Upgrade " + upgrade.String(),
+		Height:      haltHeight,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to submit upgrade proposal %s: %w", upgrade, err)
+	}
+
+	if err := chain.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes); err != nil {
+		return fmt.Errorf("failed to vote on upgrade proposal %s: %w", propTx.ProposalID, err)
+	}
+	if _, err := cosmos.PollForProposalStatus(ctx, chain, height, haltHeight-1, propTx.ProposalID, cosmos.ProposalStatusPassed); err != nil {
+		return fmt.Errorf("upgrade proposal %s did not pass before the halt height %d: %w", propTx.ProposalID, haltHeight, err)
+	}
+	return nil
+}
+
+// WaitForHalt waits until the chain halts for the upgrade at the halt height.
+func WaitForHalt(ctx context.Context, chain *cosmos.CosmosChain, haltHeight uint64) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(stateWatcherPollInterval)
+	defer ticker.Stop()
+
+	chainID := chain.Config().ChainID
+	var lastHeight uint64
+	var since time.Time
+	for {
+		// the node stops at the block before the halt height, or right at it, depending on the version
+		height, err := chain.Height(ctx)
+		if err == nil {
+			if height > haltHeight {
+				return fmt.Errorf("%s went past the halt height %d, at %d", chainID, haltHeight, height)
+			}
+			if height != lastHeight {
+				lastHeight, since = height, time.Now()
+			} else if height+1 >= haltHeight && time.Since(since) >= chainHaltStall {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s to halt at height %d, at %d: %w", chainID, haltHeight, lastHeight, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// SwapImage restarts every node of the chain on the image of the new release.
+func SwapImage(ctx context.Context, chain *cosmos.CosmosChain, cli *client.Client, upgrade ChainUpgrade) error {
+	if err := chain.StopAllNodes(ctx); err != nil {
+		return fmt.Errorf("failed to stop %s nodes: %w", chain.Config().ChainID, err)
+	}
+	chain.UpgradeVersion(ctx, cli, upgrade.Image.Repository, upgrade.ToVersion)
+	if err := chain.StartAllNodes(ctx); err != nil {
+		return fmt.Errorf("failed to start %s nodes on %s:%s: %w", chain.Config().ChainID, upgrade.Image.Repository, upgrade.ToVersion, err)
+	}
+	return nil
+}
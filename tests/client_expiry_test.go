@@ -0,0 +1,516 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	// clientTrustingPeriod is short enough for the clients of the channel to expire within the test
+	clientTrustingPeriod = "3m"
+	// substitutePath holds the clients created to substitute the expired ones
+	substitutePath = "substitute-path"
+)
+
+// This test case creates the clients of the channel with a short trusting period and stops the relayer until
+// both the hub client of the rollapp and the rollapp client of the hub expire. Transfers are then refused with
+// the client status, until each client is substituted with a fresh one through a client update proposal, after
+// which transfers go through again in both directions
+func TestClientExpiryRecovery_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+			CreateClientOpts: ibc.CreateClientOptions{
+				TrustingPeriod: clientTrustingPeriod,
+			},
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	transferAmount := math.NewInt(1_000_000)
+
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	hubTransfer := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	rollappTransfer := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+
+	// The channel works while the clients are fresh
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+
+	hubClientID, err := ChannelClientID(ctx, r, eRep, dymension.Config().ChainID, channel.Counterparty.ChannelID)
+	require.NoError(t, err)
+	rollappClientID, err := ChannelClientID(ctx, r, eRep, rollapp1.Config().ChainID, channel.ChannelID)
+	require.NoError(t, err)
+
+	// Without the relayer nothing updates the clients, both expire once the trusting period elapses
+	err = r.StopRelayer(ctx, eRep)
+	require.NoError(t, err)
+
+	trustingPeriod, err := time.ParseDuration(clientTrustingPeriod)
+	require.NoError(t, err)
+	expiryCtx, cancel := context.WithTimeout(ctx, 2*trustingPeriod)
+	defer cancel()
+	err = WaitForClientStatus(expiryCtx, dymension.CosmosChain, hubClientID, clientStatusExpired)
+	require.NoError(t, err)
+	err = WaitForClientStatus(expiryCtx, rollapp1.CosmosChain, rollappClientID, clientStatusExpired)
+	require.NoError(t, err)
+
+	// No packet can be sent on a channel whose client expired
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.ErrorContains(t, err, clientStatusExpired)
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, rollappTransfer, ibc.TransferOptions{})
+	require.ErrorContains(t, err, clientStatusExpired)
+
+	// Create fresh clients on a path of their own, so the path of the channel keeps its clients, and substitute
+	// the expired clients with them by governance
+	err = r.GeneratePath(ctx, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID, substitutePath)
+	require.NoError(t, err)
+	err = r.CreateClients(ctx, eRep, substitutePath, ibc.CreateClientOptions{TrustingPeriod: "112h"})
+	require.NoError(t, err)
+
+	hubSubstituteID, err := LatestClientID(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.NotEqual(t, hubClientID, hubSubstituteID)
+	rollappSubstituteID, err := LatestClientID(ctx, r, eRep, rollapp1.Config().ChainID, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.NotEqual(t, rollappClientID, rollappSubstituteID)
+
+	err = SubstituteClient(ctx, dymension.CosmosChain, dymensionUser.KeyName(), hubClientID, hubSubstituteID)
+	require.NoError(t, err)
+	err = SubstituteClient(ctx, rollapp1.CosmosChain, rollappUser.KeyName(), rollappClientID, rollappSubstituteID)
+	require.NoError(t, err)
+
+	status, err := QueryClientStatus(ctx, dymension.CosmosChain, hubClientID)
+	require.NoError(t, err)
+	require.Equal(t, clientStatusActive, status)
+	status, err = QueryClientStatus(ctx, rollapp1.CosmosChain, rollappClientID)
+	require.NoError(t, err)
+	require.Equal(t, clientStatusActive, status)
+
+	// The channel works again over the recovered clients
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, rollappTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount.MulRaw(2))
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+}
+
+func TestClientExpiryRecovery_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       cosmos.ModifyGenesis(rollappWasmUpgradeGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+			CreateClientOpts: ibc.CreateClientOptions{
+				TrustingPeriod: clientTrustingPeriod,
+			},
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	transferAmount := math.NewInt(1_000_000)
+
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	hubTransfer := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	rollappTransfer := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+
+	// The channel works while the clients are fresh
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+
+	hubClientID, err := ChannelClientID(ctx, r, eRep, dymension.Config().ChainID, channel.Counterparty.ChannelID)
+	require.NoError(t, err)
+	rollappClientID, err := ChannelClientID(ctx, r, eRep, rollapp1.Config().ChainID, channel.ChannelID)
+	require.NoError(t, err)
+
+	// Without the relayer nothing updates the clients, both expire once the trusting period elapses
+	err = r.StopRelayer(ctx, eRep)
+	require.NoError(t, err)
+
+	trustingPeriod, err := time.ParseDuration(clientTrustingPeriod)
+	require.NoError(t, err)
+	expiryCtx, cancel := context.WithTimeout(ctx, 2*trustingPeriod)
+	defer cancel()
+	err = WaitForClientStatus(expiryCtx, dymension.CosmosChain, hubClientID, clientStatusExpired)
+	require.NoError(t, err)
+	err = WaitForClientStatus(expiryCtx, rollapp1.CosmosChain, rollappClientID, clientStatusExpired)
+	require.NoError(t, err)
+
+	// No packet can be sent on a channel whose client expired
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.ErrorContains(t, err, clientStatusExpired)
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, rollappTransfer, ibc.TransferOptions{})
+	require.ErrorContains(t, err, clientStatusExpired)
+
+	// Create fresh clients on a path of their own, so the path of the channel keeps its clients, and substitute
+	// the expired clients with them by governance
+	err = r.GeneratePath(ctx, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID, substitutePath)
+	require.NoError(t, err)
+	err = r.CreateClients(ctx, eRep, substitutePath, ibc.CreateClientOptions{TrustingPeriod: "112h"})
+	require.NoError(t, err)
+
+	hubSubstituteID, err := LatestClientID(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.NotEqual(t, hubClientID, hubSubstituteID)
+	rollappSubstituteID, err := LatestClientID(ctx, r, eRep, rollapp1.Config().ChainID, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.NotEqual(t, rollappClientID, rollappSubstituteID)
+
+	err = SubstituteClient(ctx, dymension.CosmosChain, dymensionUser.KeyName(), hubClientID, hubSubstituteID)
+	require.NoError(t, err)
+	err = SubstituteClient(ctx, rollapp1.CosmosChain, rollappUser.KeyName(), rollappClientID, rollappSubstituteID)
+	require.NoError(t, err)
+
+	status, err := QueryClientStatus(ctx, dymension.CosmosChain, hubClientID)
+	require.NoError(t, err)
+	require.Equal(t, clientStatusActive, status)
+	status, err = QueryClientStatus(ctx, rollapp1.CosmosChain, rollappClientID)
+	require.NoError(t, err)
+	require.Equal(t, clientStatusActive, status)
+
+	// The channel works again over the recovered clients
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, rollappTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount.MulRaw(2))
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+}
@@ -0,0 +1,108 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/dockerutil"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/client"
+)
+
+const (
+	// crashSignal is the signal a crash is simulated with, no chance for the process to flush or shut down
+	crashSignal = "KILL"
+)
+
+// StopNode gracefully stops the container of a hub or rollapp node, keeping its home dir for a restart.
+func StopNode(ctx context.Context, node *cosmos.Node) error {
+	if err := node.StopContainer(ctx); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", node.Name(), err)
+	}
+	return nil
+}
+
+// KillNode kills the process of a hub or rollapp node with SIGKILL, as a crash would.
+func KillNode(ctx context.Context, node *cosmos.Node) error {
+	if err := node.DockerClient.ContainerKill(ctx, node.ContainerID(), crashSignal); err != nil {
+		return fmt.Errorf("failed to kill %s: %w", node.Name(), err)
+	}
+	return nil
+}
+
+// RestartNode recreates the container of the node, so it restarts from its home dir and picks up any change in
+// the role the hub gives it. The node may be running, stopped or killed.
+func RestartNode(ctx context.Context, node *cosmos.Node) error {
+	if err := node.StopContainer(ctx); err != nil {
+		return fmt.Errorf("failed to stop %s: %w", node.Name(), err)
+	}
+	if err := node.RemoveContainer(ctx); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", node.Name(), err)
+	}
+	if err := node.CreateNodeContainer(ctx); err != nil {
+		return fmt.Errorf("failed to create %s: %w", node.Name(), err)
+	}
+	if err := node.StartContainer(ctx); err != nil {
+		return fmt.Errorf("failed to start %s: %w", node.Name(), err)
+	}
+	return nil
+}
+
+// relayerHost is implemented by the docker relayers, whose containers are reachable by a host name per path.
+type relayerHost interface {
+	HostName(pathName string) string
+}
+
+// KillRelayer kills the relayer process relaying the path with SIGKILL, as a crash would. The relayer keeps its
+// handle on the container, call RestartRelayer to bring it back.
+func KillRelayer(ctx context.Context, cli *client.Client, r ibc.Relayer, testName, pathName string) error {
+	containerID, err := RelayerContainerID(ctx, cli, r, testName, pathName)
+	if err != nil {
+		return err
+	}
+	if err := cli.ContainerKill(ctx, containerID, crashSignal); err != nil {
+		return fmt.Errorf("failed to kill relayer of path %s: %w", pathName, err)
+	}
+	return nil
+}
+
+// RestartRelayer stops the relayer, running or killed, and starts it again on the path.
+func RestartRelayer(ctx context.Context, r ibc.Relayer, rep ibc.RelayerExecReporter, pathName string) error {
+	if err := r.StopRelayer(ctx, rep); err != nil {
+		return fmt.Errorf("failed to stop relayer of path %s: %w", pathName, err)
+	}
+	if err := r.StartRelayer(ctx, rep, pathName); err != nil {
+		return fmt.Errorf("failed to start relayer of path %s: %w", pathName, err)
+	}
+	return nil
+}
+
+// RelayerContainerID finds the running container of the relayer among the containers of the test by the host
+// name the relayer gives it.
+func RelayerContainerID(ctx context.Context, cli *client.Client, r ibc.Relayer, testName, pathName string) (string, error) {
+	host, ok := r.(relayerHost)
+	if !ok {
+		return "", fmt.Errorf("relayer %T does not run in docker", r)
+	}
+	hostName := host.HostName(pathName)
+
+	containers, err := cli.ContainerList(ctx, types.ContainerListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", dockerutil.CleanupLabel+"="+testName)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list containers of %s: %w", testName, err)
+	}
+	for _, c := range containers {
+		info, err := cli.ContainerInspect(ctx, c.ID)
+		if err != nil {
+			return "", fmt.Errorf("failed to inspect container %s: %w", c.ID, err)
+		}
+		if info.Config != nil && info.Config.Hostname == hostName {
+			return c.ID, nil
+		}
+	}
+	return "", fmt.Errorf("no running relayer container of path %s in %s", pathName, testName)
+}
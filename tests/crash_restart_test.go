@@ -0,0 +1,1308 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// This test case kills the sequencer with SIGKILL partway through a batch, while it holds blocks it has not
+// submitted yet, and restarts it. The rollapp resumes submitting from where it stopped without a gap in the batch
+// history, the batches submitted after the crash are finalized, and a transfer sent right before the crash
+// reaches the hub
+func TestSequencerCrash_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	sequencer := rollapp1.Validators[0]
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+
+	transferAmount := math.NewInt(1_000_000)
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// Right after a batch, send a transfer whose block goes into the next one
+	batchCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForNextBatch(batchCtx)
+	require.NoError(t, err)
+
+	transferData := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 2, rollapp1)
+	require.NoError(t, err)
+
+	// Crash the sequencer mid-batch
+	crashHeight, err := sequencer.Height(ctx)
+	require.NoError(t, err)
+	err = KillNode(ctx, sequencer)
+	require.NoError(t, err)
+
+	// Nothing is submitted while the sequencer is down
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+	downIndex, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 10, dymension)
+	require.NoError(t, err)
+	latestIndex, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+	require.Equal(t, downIndex, latestIndex, "state updates submitted while the sequencer was down")
+
+	err = RestartNode(ctx, sequencer)
+	require.NoError(t, err)
+
+	// The blocks produced before the crash are submitted, and the rollapp keeps going past them
+	batchCtx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForBatchCovering(batchCtx, crashHeight)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, rollapp1)
+	require.NoError(t, err)
+	resumedHeight, err := sequencer.Height(ctx)
+	require.NoError(t, err)
+	require.Greater(t, resumedHeight, crashHeight)
+
+	batchCtx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForBatchCovering(batchCtx, resumedHeight)
+	require.NoError(t, err)
+
+	report, err := AnalyzeBatchHistory(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.NoError(t, report.Err())
+
+	// Finalization continues past the crash and releases the transfer
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, resumedHeight)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+}
+
+func TestSequencerCrash_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	sequencer := rollapp1.Validators[0]
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+
+	transferAmount := math.NewInt(1_000_000)
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// Right after a batch, send a transfer whose block goes into the next one
+	batchCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForNextBatch(batchCtx)
+	require.NoError(t, err)
+
+	transferData := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 2, rollapp1)
+	require.NoError(t, err)
+
+	// Crash the sequencer mid-batch
+	crashHeight, err := sequencer.Height(ctx)
+	require.NoError(t, err)
+	err = KillNode(ctx, sequencer)
+	require.NoError(t, err)
+
+	// Nothing is submitted while the sequencer is down
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+	downIndex, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 10, dymension)
+	require.NoError(t, err)
+	latestIndex, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+	require.Equal(t, downIndex, latestIndex, "state updates submitted while the sequencer was down")
+
+	err = RestartNode(ctx, sequencer)
+	require.NoError(t, err)
+
+	// The blocks produced before the crash are submitted, and the rollapp keeps going past them
+	batchCtx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForBatchCovering(batchCtx, crashHeight)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, rollapp1)
+	require.NoError(t, err)
+	resumedHeight, err := sequencer.Height(ctx)
+	require.NoError(t, err)
+	require.Greater(t, resumedHeight, crashHeight)
+
+	batchCtx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForBatchCovering(batchCtx, resumedHeight)
+	require.NoError(t, err)
+
+	report, err := AnalyzeBatchHistory(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.NoError(t, report.Err())
+
+	// Finalization continues past the crash and releases the transfer
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, resumedHeight)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+}
+
+// This test case kills the hub validator with SIGKILL while a batch and a transfer of the rollapp are within their
+// dispute period, and restarts it. The pending batch is finalized once its dispute period has passed, the
+// sequencer keeps submitting batches without a gap, and the transfer is released to the recipient
+func TestHubRestartDuringDisputePeriod_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	validator := dymension.Validators[0]
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+
+	transferAmount := math.NewInt(1_000_000)
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	transferData := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	transferHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	// Wait for the transfer to be submitted, so both the batch and the packet are in their dispute period
+	batchCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	pending, err := stateWatcher.WaitForBatchCovering(batchCtx, transferHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, pending.Status)
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, math.ZeroInt())
+
+	// Crash the hub and bring it back while the batch is pending
+	err = KillNode(ctx, validator)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 5, rollapp1)
+	require.NoError(t, err)
+
+	err = RestartNode(ctx, validator)
+	require.NoError(t, err)
+
+	// The pending batch is finalized and the sequencer keeps submitting
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	finalized, err := stateWatcher.WaitForStatus(finalizeCtx, pending.Index, stateStatusFinalized)
+	require.NoError(t, err)
+	require.Equal(t, pending.Index, finalized.Index)
+
+	batchCtx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForIndex(batchCtx, finalized.Index+2)
+	require.NoError(t, err)
+
+	report, err := AnalyzeBatchHistory(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.NoError(t, report.Err())
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// Batches submitted after the restart are finalized too
+	latestHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+	finalizeCtx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, latestHeight)
+	require.NoError(t, err)
+}
+
+func TestHubRestartDuringDisputePeriod_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	validator := dymension.Validators[0]
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+
+	transferAmount := math.NewInt(1_000_000)
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	transferData := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	transferHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	// Wait for the transfer to be submitted, so both the batch and the packet are in their dispute period
+	batchCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	pending, err := stateWatcher.WaitForBatchCovering(batchCtx, transferHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, pending.Status)
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, math.ZeroInt())
+
+	// Crash the hub and bring it back while the batch is pending
+	err = KillNode(ctx, validator)
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 5, rollapp1)
+	require.NoError(t, err)
+
+	err = RestartNode(ctx, validator)
+	require.NoError(t, err)
+
+	// The pending batch is finalized and the sequencer keeps submitting
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	finalized, err := stateWatcher.WaitForStatus(finalizeCtx, pending.Index, stateStatusFinalized)
+	require.NoError(t, err)
+	require.Equal(t, pending.Index, finalized.Index)
+
+	batchCtx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForIndex(batchCtx, finalized.Index+2)
+	require.NoError(t, err)
+
+	report, err := AnalyzeBatchHistory(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.NoError(t, report.Err())
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// Batches submitted after the restart are finalized too
+	latestHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+	finalizeCtx, cancel = context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, latestHeight)
+	require.NoError(t, err)
+}
+
+// This test case sends transfers in both directions and kills the relayer with SIGKILL before it relays them,
+// then does the same with a graceful stop. Once the relayer is restarted every packet is received and
+// acknowledged exactly once, and the balances on both sides add up to the amounts sent
+func TestRelayerRestart_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	const transfersPerRound = 3
+	transferAmount := math.NewInt(1_000_000)
+
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	hubStartHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+	rollappStartHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	var hubSequences, rollappSequences []uint64
+	for _, kill := range []bool{true, false} {
+		// Send the packets while the relayer runs, then take it down before it relays them
+		for i := 0; i < transfersPerRound; i++ {
+			transferData := ibc.WalletData{
+				Address: rollappUserAddr,
+				Denom:   dymension.Config().Denom,
+				Amount:  transferAmount,
+			}
+			tx, err := dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+			require.NoError(t, err)
+			hubSequences = append(hubSequences, tx.Packet.Sequence)
+
+			transferData = ibc.WalletData{
+				Address: dymensionUserAddr,
+				Denom:   rollapp1.Config().Denom,
+				Amount:  transferAmount,
+			}
+			tx, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+			require.NoError(t, err)
+			rollappSequences = append(rollappSequences, tx.Packet.Sequence)
+		}
+
+		if kill {
+			err = KillRelayer(ctx, client, r, t.Name(), ibcPath)
+		} else {
+			err = r.StopRelayer(ctx, eRep)
+		}
+		require.NoError(t, err)
+
+		err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+		require.NoError(t, err)
+
+		err = RestartRelayer(ctx, r, eRep, ibcPath)
+		require.NoError(t, err)
+	}
+
+	// The hub transfers arrive right away, the rollapp transfers once their heights are finalized
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	sent := transferAmount.MulRaw(int64(len(hubSequences)))
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, sent)
+
+	rollappHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	// Leave time for the acknowledgements released by the finalization to be relayed back
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	sent = transferAmount.MulRaw(int64(len(rollappSequences)))
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, sent)
+
+	// Every packet was received and acknowledged exactly once
+	hubEndHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+	rollappEndHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	requireRelayedOnce(t, ctx, rollapp1.CosmosChain, rollappStartHeight, rollappEndHeight, packetEventRecv, channel.ChannelID, hubSequences)
+	requireRelayedOnce(t, ctx, dymension.CosmosChain, hubStartHeight, hubEndHeight, packetEventAck, channel.Counterparty.ChannelID, hubSequences)
+	requireRelayedOnce(t, ctx, dymension.CosmosChain, hubStartHeight, hubEndHeight, packetEventRecv, channel.Counterparty.ChannelID, rollappSequences)
+	requireRelayedOnce(t, ctx, rollapp1.CosmosChain, rollappStartHeight, rollappEndHeight, packetEventAck, channel.ChannelID, rollappSequences)
+}
+
+func TestRelayerRestart_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	const transfersPerRound = 3
+	transferAmount := math.NewInt(1_000_000)
+
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	hubStartHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+	rollappStartHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	var hubSequences, rollappSequences []uint64
+	for _, kill := range []bool{true, false} {
+		// Send the packets while the relayer runs, then take it down before it relays them
+		for i := 0; i < transfersPerRound; i++ {
+			transferData := ibc.WalletData{
+				Address: rollappUserAddr,
+				Denom:   dymension.Config().Denom,
+				Amount:  transferAmount,
+			}
+			tx, err := dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+			require.NoError(t, err)
+			hubSequences = append(hubSequences, tx.Packet.Sequence)
+
+			transferData = ibc.WalletData{
+				Address: dymensionUserAddr,
+				Denom:   rollapp1.Config().Denom,
+				Amount:  transferAmount,
+			}
+			tx, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+			require.NoError(t, err)
+			rollappSequences = append(rollappSequences, tx.Packet.Sequence)
+		}
+
+		if kill {
+			err = KillRelayer(ctx, client, r, t.Name(), ibcPath)
+		} else {
+			err = r.StopRelayer(ctx, eRep)
+		}
+		require.NoError(t, err)
+
+		err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+		require.NoError(t, err)
+
+		err = RestartRelayer(ctx, r, eRep, ibcPath)
+		require.NoError(t, err)
+	}
+
+	// The hub transfers arrive right away, the rollapp transfers once their heights are finalized
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	sent := transferAmount.MulRaw(int64(len(hubSequences)))
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, sent)
+
+	rollappHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	// Leave time for the acknowledgements released by the finalization to be relayed back
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	sent = transferAmount.MulRaw(int64(len(rollappSequences)))
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, sent)
+
+	// Every packet was received and acknowledged exactly once
+	hubEndHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+	rollappEndHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	requireRelayedOnce(t, ctx, rollapp1.CosmosChain, rollappStartHeight, rollappEndHeight, packetEventRecv, channel.ChannelID, hubSequences)
+	requireRelayedOnce(t, ctx, dymension.CosmosChain, hubStartHeight, hubEndHeight, packetEventAck, channel.Counterparty.ChannelID, hubSequences)
+	requireRelayedOnce(t, ctx, dymension.CosmosChain, hubStartHeight, hubEndHeight, packetEventRecv, channel.Counterparty.ChannelID, rollappSequences)
+	requireRelayedOnce(t, ctx, rollapp1.CosmosChain, rollappStartHeight, rollappEndHeight, packetEventAck, channel.ChannelID, rollappSequences)
+}
+
+// requireRelayedOnce asserts that the chain emitted exactly one packet event of the given type for each sequence.
+func requireRelayedOnce(t *testing.T, ctx context.Context, chain *cosmos.CosmosChain, startHeight, endHeight uint64, eventType, channelID string, sequences []uint64) {
+	counts, err := CountPacketEvents(ctx, chain, startHeight, endHeight, eventType, channelID)
+	require.NoError(t, err)
+	for _, sequence := range sequences {
+		require.Equal(t, 1, counts[sequence], "%s events of packet %d on %s %s", eventType, sequence, chain.Config().ChainID, channelID)
+	}
+}
@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultDemandOrderWaitTimeout bounds waits for demand orders whose context has no deadline of its own.
+const defaultDemandOrderWaitTimeout = 2 * time.Minute
+
+// DemandOrder is an eIBC demand order as stored on the hub.
+type DemandOrder struct {
+	ID string
+	// TrackingPacketKey is the key of the rollapp packet the order was created for. It embeds binary heights and
+	// sequences, so it is only meant to be compared, not parsed.
+	TrackingPacketKey string
+	Price             sdk.Coins
+	Fee               sdk.Coins
+	Recipient         string
+	// RollappID is only reported by hub versions that store it on the order, see BelongsTo
+	RollappID    string
+	IsFulfilled  bool
+	PacketStatus string
+}
+
+// BelongsTo reports whether the order was created for a packet of the given rollapp.
+// Hubs that do not report the rollapp of an order still embed it in the tracking packet key.
+func (o DemandOrder) BelongsTo(rollappID string) bool {
+	if o.RollappID != "" {
+		return o.RollappID == rollappID
+	}
+	return strings.Contains(o.TrackingPacketKey, "/"+rollappID+"/")
+}
+
+func (o DemandOrder) String() string {
+	return fmt.Sprintf("demand order %s (price %s, fee %s, recipient %s, fulfilled %t, packet status %s)",
+		o.ID, o.Price, o.Fee, o.Recipient, o.IsFulfilled, o.PacketStatus)
+}
+
+// demandOrderResponse decodes the demand orders of every hub version the tests run against.
+type demandOrderResponse struct {
+	ID                   string    `json:"id"`
+	TrackingPacketKey    string    `json:"tracking_packet_key"`
+	Price                sdk.Coins `json:"price"`
+	Fee                  sdk.Coins `json:"fee"`
+	Recipient            string    `json:"recipient"`
+	RollappID            string    `json:"rollapp_id"`
+	IsFullfilled         bool      `json:"is_fullfilled"`
+	IsFulfilled          bool      `json:"is_fulfilled"`
+	TrackingPacketStatus string    `json:"tracking_packet_status"`
+}
+
+// DemandOrderFilter selects demand orders in QueryDemandOrders and the order-book assertions.
+type DemandOrderFilter func(DemandOrder) bool
+
+func ByRecipient(recipient string) DemandOrderFilter {
+	return func(o DemandOrder) bool { return o.Recipient == recipient }
+}
+
+func ByRollapp(rollappID string) DemandOrderFilter {
+	return func(o DemandOrder) bool { return o.BelongsTo(rollappID) }
+}
+
+func ByPacketKey(trackingPacketKey string) DemandOrderFilter {
+	return func(o DemandOrder) bool { return o.TrackingPacketKey == trackingPacketKey }
+}
+
+func ByFulfilled(fulfilled bool) DemandOrderFilter {
+	return func(o DemandOrder) bool { return o.IsFulfilled == fulfilled }
+}
+
+// QueryDemandOrders lists the demand orders whose tracked packet has the given status (PENDING, FINALIZED or
+// REVERTED) and that match every filter, sorted as returned by the hub.
+func QueryDemandOrders(ctx context.Context, hub *dym_hub.DymHub, status string, filters ...DemandOrderFilter) ([]DemandOrder, error) {
+	stdout, _, err := hub.GetNode().ExecQuery(ctx, "eibc", "list-demand-orders", strings.ToLower(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s demand orders: %w", status, err)
+	}
+
+	var res struct {
+		DemandOrders []demandOrderResponse `json:"demand_orders"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s demand orders: %w", status, err)
+	}
+
+	var orders []DemandOrder
+	for _, o := range res.DemandOrders {
+		order := DemandOrder{
+			ID:                o.ID,
+			TrackingPacketKey: o.TrackingPacketKey,
+			Price:             o.Price,
+			Fee:               o.Fee,
+			Recipient:         o.Recipient,
+			RollappID:         o.RollappID,
+			IsFulfilled:       o.IsFullfilled || o.IsFulfilled,
+			PacketStatus:      o.TrackingPacketStatus,
+		}
+		if matchesDemandOrder(order, filters) {
+			orders = append(orders, order)
+		}
+	}
+	return orders, nil
+}
+
+func matchesDemandOrder(order DemandOrder, filters []DemandOrderFilter) bool {
+	for _, filter := range filters {
+		if !filter(order) {
+			return false
+		}
+	}
+	return true
+}
+
+// QueryDemandOrder returns the demand order with the given id, whatever the status of its packet.
+func QueryDemandOrder(ctx context.Context, hub *dym_hub.DymHub, id string) (*DemandOrder, error) {
+	for _, status := range []string{stateStatusPending, stateStatusFinalized, stateStatusReverted} {
+		orders, err := QueryDemandOrders(ctx, hub, status, func(o DemandOrder) bool { return o.ID == id })
+		if err != nil {
+			return nil, err
+		}
+		if len(orders) > 0 {
+			return &orders[0], nil
+		}
+	}
+	return nil, fmt.Errorf("demand order %s not found", id)
+}
+
+// FulfillDemandOrder fulfills the order with the key from the node, whose keyring must hold it, and returns the
+// result of the transaction as executed in its block. Unlike the FullfillDemandOrder of the hub, it does not go
+// through the first full node, so fulfillments from different nodes are broadcast concurrently. A fulfillment
+// rejected by the eibc module, such as one of an order already fulfilled, is a failed result, not an error.
+func FulfillDemandOrder(ctx context.Context, hub *dym_hub.DymHub, node *cosmos.Node, orderID, keyName string) (*sdk.TxResponse, error) {
+	txHash, err := node.ExecTx(ctx, keyName, "eibc", "fulfill-order", orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast fulfillment of demand order %s by %s: %w", orderID, keyName, err)
+	}
+	return TxResult(hub.CosmosChain, txHash)
+}
+
+// WaitForDemandOrders waits until at least n demand orders with the given packet status match the filters,
+// and returns all the matching ones.
+func WaitForDemandOrders(ctx context.Context, hub *dym_hub.DymHub, status string, n int, filters ...DemandOrderFilter) ([]DemandOrder, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultDemandOrderWaitTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(stateWatcherPollInterval)
+	defer ticker.Stop()
+
+	var (
+		orders  []DemandOrder
+		lastErr error
+	)
+	for {
+		found, err := QueryDemandOrders(ctx, hub, status, filters...)
+		if err != nil {
+			lastErr = err
+		} else {
+			orders = found
+			if len(orders) >= n {
+				return orders, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for %d %s demand orders, found %d %v: %w (last query error: %v)",
+				n, status, len(orders), orders, ctx.Err(), lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// RequireDemandOrders asserts that exactly n demand orders with the given packet status match the filters,
+// and returns them.
+func RequireDemandOrders(t *testing.T, ctx context.Context, hub *dym_hub.DymHub, status string, n int, filters ...DemandOrderFilter) []DemandOrder {
+	orders, err := QueryDemandOrders(ctx, hub, status, filters...)
+	require.NoError(t, err)
+	require.Len(t, orders, n, "unexpected %s demand orders: %v", status, orders)
+	return orders
+}
+
+// RequireUnfulfilledOrders asserts that the order book holds exactly n pending, unfulfilled demand orders for
+// the rollapp, and returns them.
+func RequireUnfulfilledOrders(t *testing.T, ctx context.Context, hub *dym_hub.DymHub, rollappID string, n int) []DemandOrder {
+	return RequireDemandOrders(t, ctx, hub, stateStatusPending, n, ByRollapp(rollappID), ByFulfilled(false))
+}
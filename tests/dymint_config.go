@@ -0,0 +1,54 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+)
+
+// DymintConfig is the part of dymint.toml a test sets on the nodes of a rollapp.
+type DymintConfig struct {
+	SettlementLayer string
+	// NodeAddress is the RPC address of the hub node the rollapp settles on
+	NodeAddress string
+	RollappID   string
+	GasPrices   string
+	// DALayer and DAConfig select the DA the rollapp submits its batches to, the image default when empty
+	DALayer  string
+	DAConfig string
+}
+
+// NewDymintConfig returns the dymint config every test uses, settling the rollapp on the first hub validator.
+func NewDymintConfig(t *testing.T, rollappID string) DymintConfig {
+	return DymintConfig{
+		SettlementLayer: "dymension",
+		NodeAddress:     fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name()),
+		RollappID:       rollappID,
+		GasPrices:       "0adym",
+	}
+}
+
+// WithMockDA points the rollapp at the mock DA.
+func (c DymintConfig) WithMockDA(da *MockDA) DymintConfig {
+	c.DALayer = da.DALayer()
+	c.DAConfig = da.DAConfig()
+	return c
+}
+
+// ConfigFileOverrides returns the config file overrides of the rollapp chain config.
+func (c DymintConfig) ConfigFileOverrides() map[string]any {
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = c.SettlementLayer
+	dymintTomlOverrides["node_address"] = c.NodeAddress
+	dymintTomlOverrides["rollapp_id"] = c.RollappID
+	dymintTomlOverrides["gas_prices"] = c.GasPrices
+	if c.DALayer != "" {
+		dymintTomlOverrides["da_layer"] = c.DALayer
+		dymintTomlOverrides["da_config"] = c.DAConfig
+	}
+
+	configFileOverrides := make(map[string]any)
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	return configFileOverrides
+}
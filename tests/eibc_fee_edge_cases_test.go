@@ -0,0 +1,649 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	dymensiontesting "github.com/decentrio/rollup-e2e-testing/dymension"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+const (
+	// eibcFeeTestAmount is the amount sent by every fee edge case
+	eibcFeeTestAmount = 1_000_000
+	// eibcRejectedAck is the error acknowledgement the hub writes when it cannot create a demand order for a
+	// packet. The hub rejects fees with errors that have no ABCI code of their own and emits no event with them,
+	// so every rejection carries the same acknowledgement.
+	eibcRejectedAck = `{"error":"ABCI code: 1: error handling packet: see events for details"}`
+)
+
+// eibcFeeCase is a rollapp -> hub transfer sent with a given eIBC fee and the outcome expected on the hub.
+type eibcFeeCase struct {
+	name string
+	fee  string
+	// hubToken sends back hub tokens held on the rollapp instead of the rollapp native token
+	hubToken bool
+	// expOrder is whether the hub creates a demand order for the transfer
+	expOrder bool
+	// expAckErr is the error acknowledgement written by the hub, empty if the transfer is accepted
+	expAckErr string
+}
+
+// eibcFeeCases covers the fees the hub has to make sense of. A fee that is not an integer of at most 256 bits fails
+// the memo validation and the transfer goes through as a regular delayed transfer, while an integer the hub cannot
+// price an order with makes it reject the transfer. The reason of a rejection reaches neither the acknowledgement
+// nor the events, so each rejected fee sits next to an accepted one at the boundary of its rule.
+var eibcFeeCases = []eibcFeeCase{
+	{name: "valid_fee", fee: "100000", expOrder: true},
+	{name: "zero_fee", fee: "0", expAckErr: eibcRejectedAck},
+	{name: "smallest_fee", fee: "1", expOrder: true},
+	{name: "negative_fee", fee: "-100000", expAckErr: eibcRejectedAck},
+	{name: "fee_equal_to_amount", fee: fmt.Sprint(eibcFeeTestAmount), expOrder: true},
+	{name: "fee_greater_than_amount", fee: fmt.Sprint(eibcFeeTestAmount + 1), expAckErr: eibcRejectedAck},
+	{name: "non_numeric_fee", fee: "one-tenth"},
+	{name: "oversized_fee", fee: strings.Repeat("9", 100)},
+	{name: "fee_on_non_native_denom", fee: "100000", hubToken: true, expOrder: true},
+}
+
+// This test case sends rollapp -> hub transfers with edge case eIBC fees and verifies, for each of them, whether a
+// demand order is created, the acknowledgement written by the hub, and what the recipient gets before and after
+// the rollapp height is finalized
+func TestEIBCFeeEdgeCases_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	const BLOCK_FINALITY_PERIOD = 20
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom for urax on Hub
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+	// Get the IBC denom for adym on the rollapp
+	dymensionTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	dymensionIBCDenom := transfertypes.ParseDenomTrace(dymensionTokenDenom).IBCDenom()
+
+	// the rollapp user needs hub tokens to send them back with a fee
+	transferAmount := math.NewInt(eibcFeeTestAmount)
+	transferData := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount.MulRaw(10),
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 10, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, dymensionIBCDenom, transferData.Amount)
+	// end of preconditions
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	for _, tc := range eibcFeeCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// the denom sent from the rollapp and the denom the recipient gets on the hub
+			sentDenom, recvDenom := rollapp1.Config().Denom, rollappIBCDenom
+			if tc.hubToken {
+				sentDenom, recvDenom = dymensionIBCDenom, dymension.Config().Denom
+			}
+
+			// every case pays a fresh recipient so its balance only reflects that case
+			recipient := test.GetAndFundTestUsers(t, ctx, tc.name, walletAmount, dymension)[0]
+			recipientAddr := recipient.FormattedAddress()
+			recipientBalance, err := dymension.GetBalance(ctx, recipientAddr, recvDenom)
+			require.NoError(t, err)
+			senderBalance, err := rollapp1.GetBalance(ctx, rollappUserAddr, sentDenom)
+			require.NoError(t, err)
+
+			hubHeight, err := dymension.Height(ctx)
+			require.NoError(t, err)
+
+			transferData := ibc.WalletData{
+				Address: recipientAddr,
+				Denom:   sentDenom,
+				Amount:  transferAmount,
+			}
+			options := ibc.TransferOptions{Memo: BuildEIbcMemoFromString(tc.fee)}
+			tx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, options)
+			require.NoError(t, err)
+			rollappHeight, err := rollapp1.GetNode().Height(ctx)
+			require.NoError(t, err)
+
+			if tc.expOrder {
+				orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+				defer cancel()
+				_, err = WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(recipientAddr))
+				require.NoError(t, err, "no demand order was created for fee %q", tc.fee)
+			}
+			err = testutil.WaitForBlocks(ctx, 10, dymension)
+			require.NoError(t, err)
+			hubHeightAfter, err := dymension.Height(ctx)
+			require.NoError(t, err)
+
+			// the hub either holds the transfer until finalization or rejects it right away
+			ack, found, err := findWriteAcknowledgement(dymension, hubHeight, hubHeightAfter, tx.Packet)
+			require.NoError(t, err)
+			if tc.expAckErr == "" {
+				require.False(t, found, "transfer with fee %q was acknowledged before finalization: %s", tc.fee, ack)
+				RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet)
+			} else {
+				require.True(t, found, "transfer with fee %q was not rejected", tc.fee)
+				require.Equal(t, tc.expAckErr, ack, "transfer with fee %q rejected with another error", tc.fee)
+				_, err := FindRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet, 0)
+				require.ErrorIs(t, err, errRollappPacketNotFound, "rejected transfer with fee %q is held by the hub", tc.fee)
+			}
+
+			orders := RequireDemandOrders(t, ctx, dymension, stateStatusPending, boolToInt(tc.expOrder), ByRecipient(recipientAddr))
+			if tc.expOrder {
+				order := orders[0]
+				t.Logf("Demand order for fee %q: %s", tc.fee, order)
+				fee, ok := math.NewIntFromString(tc.fee)
+				require.True(t, ok)
+				require.False(t, order.IsFulfilled)
+				require.True(t, order.BelongsTo(rollapp1.GetChainID()))
+				require.True(t, order.Fee.AmountOf(recvDenom).Equal(fee), "unexpected fee %s", order.Fee)
+				require.True(t, order.Price.AmountOf(recvDenom).Equal(transferAmount.Sub(fee)), "unexpected price %s", order.Price)
+
+				// the order is also announced in the hub events
+				eibcEvents, err := getEventsOfType(dymension.CosmosChain, hubHeight, hubHeightAfter, "eibc", false)
+				require.NoError(t, err)
+				var announced bool
+				for _, event := range eibcEvents {
+					eibcEvent, err := dymensiontesting.MapToEibcEvent(event)
+					require.NoError(t, err)
+					if eibcEvent.ID == order.ID {
+						announced = true
+						require.Equal(t, order.Fee.String(), eibcEvent.Fee)
+						require.False(t, eibcEvent.IsFulfilled)
+					}
+				}
+				require.True(t, announced, "no eibc event was emitted for %s", order)
+			}
+
+			// nothing reaches the recipient before finalization, whatever the fee
+			testutil.AssertBalance(t, ctx, dymension, recipientAddr, recvDenom, recipientBalance)
+
+			finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+			defer cancel()
+			_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+			require.NoError(t, err)
+			err = testutil.WaitForBlocks(ctx, 2, dymension)
+			require.NoError(t, err)
+
+			// an accepted transfer pays the whole amount to the recipient since nobody fulfilled its order,
+			// a rejected one is refunded to the sender on the rollapp
+			if tc.expAckErr == "" {
+				testutil.AssertBalance(t, ctx, dymension, recipientAddr, recvDenom, recipientBalance.Add(transferAmount))
+				testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, sentDenom, senderBalance.Sub(transferAmount))
+			} else {
+				testutil.AssertBalance(t, ctx, dymension, recipientAddr, recvDenom, recipientBalance)
+				testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, sentDenom, senderBalance)
+			}
+			if tc.expOrder {
+				RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(recipientAddr))
+			}
+		})
+	}
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
+
+func TestEIBCFeeEdgeCases_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	const BLOCK_FINALITY_PERIOD = 20
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom for urax on Hub
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+	// Get the IBC denom for adym on the rollapp
+	dymensionTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	dymensionIBCDenom := transfertypes.ParseDenomTrace(dymensionTokenDenom).IBCDenom()
+
+	// the rollapp user needs hub tokens to send them back with a fee
+	transferAmount := math.NewInt(eibcFeeTestAmount)
+	transferData := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount.MulRaw(10),
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 10, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, dymensionIBCDenom, transferData.Amount)
+	// end of preconditions
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	for _, tc := range eibcFeeCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			// the denom sent from the rollapp and the denom the recipient gets on the hub
+			sentDenom, recvDenom := rollapp1.Config().Denom, rollappIBCDenom
+			if tc.hubToken {
+				sentDenom, recvDenom = dymensionIBCDenom, dymension.Config().Denom
+			}
+
+			// every case pays a fresh recipient so its balance only reflects that case
+			recipient := test.GetAndFundTestUsers(t, ctx, tc.name, walletAmount, dymension)[0]
+			recipientAddr := recipient.FormattedAddress()
+			recipientBalance, err := dymension.GetBalance(ctx, recipientAddr, recvDenom)
+			require.NoError(t, err)
+			senderBalance, err := rollapp1.GetBalance(ctx, rollappUserAddr, sentDenom)
+			require.NoError(t, err)
+
+			hubHeight, err := dymension.Height(ctx)
+			require.NoError(t, err)
+
+			transferData := ibc.WalletData{
+				Address: recipientAddr,
+				Denom:   sentDenom,
+				Amount:  transferAmount,
+			}
+			options := ibc.TransferOptions{Memo: BuildEIbcMemoFromString(tc.fee)}
+			tx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, options)
+			require.NoError(t, err)
+			rollappHeight, err := rollapp1.GetNode().Height(ctx)
+			require.NoError(t, err)
+
+			if tc.expOrder {
+				orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+				defer cancel()
+				_, err = WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(recipientAddr))
+				require.NoError(t, err, "no demand order was created for fee %q", tc.fee)
+			}
+			err = testutil.WaitForBlocks(ctx, 10, dymension)
+			require.NoError(t, err)
+			hubHeightAfter, err := dymension.Height(ctx)
+			require.NoError(t, err)
+
+			// the hub either holds the transfer until finalization or rejects it right away
+			ack, found, err := findWriteAcknowledgement(dymension, hubHeight, hubHeightAfter, tx.Packet)
+			require.NoError(t, err)
+			if tc.expAckErr == "" {
+				require.False(t, found, "transfer with fee %q was acknowledged before finalization: %s", tc.fee, ack)
+				RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet)
+			} else {
+				require.True(t, found, "transfer with fee %q was not rejected", tc.fee)
+				require.Equal(t, tc.expAckErr, ack, "transfer with fee %q rejected with another error", tc.fee)
+				_, err := FindRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet, 0)
+				require.ErrorIs(t, err, errRollappPacketNotFound, "rejected transfer with fee %q is held by the hub", tc.fee)
+			}
+
+			orders := RequireDemandOrders(t, ctx, dymension, stateStatusPending, boolToInt(tc.expOrder), ByRecipient(recipientAddr))
+			if tc.expOrder {
+				order := orders[0]
+				t.Logf("Demand order for fee %q: %s", tc.fee, order)
+				fee, ok := math.NewIntFromString(tc.fee)
+				require.True(t, ok)
+				require.False(t, order.IsFulfilled)
+				require.True(t, order.BelongsTo(rollapp1.GetChainID()))
+				require.True(t, order.Fee.AmountOf(recvDenom).Equal(fee), "unexpected fee %s", order.Fee)
+				require.True(t, order.Price.AmountOf(recvDenom).Equal(transferAmount.Sub(fee)), "unexpected price %s", order.Price)
+
+				// the order is also announced in the hub events
+				eibcEvents, err := getEventsOfType(dymension.CosmosChain, hubHeight, hubHeightAfter, "eibc", false)
+				require.NoError(t, err)
+				var announced bool
+				for _, event := range eibcEvents {
+					eibcEvent, err := dymensiontesting.MapToEibcEvent(event)
+					require.NoError(t, err)
+					if eibcEvent.ID == order.ID {
+						announced = true
+						require.Equal(t, order.Fee.String(), eibcEvent.Fee)
+						require.False(t, eibcEvent.IsFulfilled)
+					}
+				}
+				require.True(t, announced, "no eibc event was emitted for %s", order)
+			}
+
+			// nothing reaches the recipient before finalization, whatever the fee
+			testutil.AssertBalance(t, ctx, dymension, recipientAddr, recvDenom, recipientBalance)
+
+			finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+			defer cancel()
+			_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+			require.NoError(t, err)
+			err = testutil.WaitForBlocks(ctx, 2, dymension)
+			require.NoError(t, err)
+
+			// an accepted transfer pays the whole amount to the recipient since nobody fulfilled its order,
+			// a rejected one is refunded to the sender on the rollapp
+			if tc.expAckErr == "" {
+				testutil.AssertBalance(t, ctx, dymension, recipientAddr, recvDenom, recipientBalance.Add(transferAmount))
+				testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, sentDenom, senderBalance.Sub(transferAmount))
+			} else {
+				testutil.AssertBalance(t, ctx, dymension, recipientAddr, recvDenom, recipientBalance)
+				testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, sentDenom, senderBalance)
+			}
+			if tc.expOrder {
+				RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(recipientAddr))
+			}
+		})
+	}
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
+
+// findWriteAcknowledgement looks for the acknowledgement the hub wrote for a packet between two heights and
+// returns it as emitted in the write_acknowledgement event.
+func findWriteAcknowledgement(hub *dym_hub.DymHub, startHeight, endHeight uint64, packet ibc.Packet) (string, bool, error) {
+	events, err := getEventsOfType(hub.CosmosChain, startHeight, endHeight, "write_acknowledgement", false)
+	if err != nil {
+		return "", false, err
+	}
+	for _, event := range events {
+		attrs := make(map[string]string, len(event.Attributes))
+		for _, attr := range event.Attributes {
+			attrs[attr.Key] = attr.Value
+		}
+		if attrs["packet_sequence"] != fmt.Sprint(packet.Sequence) ||
+			(packet.DestChannel != "" && attrs["packet_dst_channel"] != packet.DestChannel) {
+			continue
+		}
+		return attrs["packet_ack"], true, nil
+	}
+	return "", false, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"testing"
+	"time"
 
 	"cosmossdk.io/math"
 	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
@@ -110,7 +111,7 @@ func TestEIBCFulfillment_EVM(t *testing.T) {
 
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 	const ibcPath = "ibc-path"
@@ -138,6 +139,8 @@ func TestEIBCFulfillment_EVM(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	walletAmount := math.NewInt(1_000_000_000_000)
 
 	// Create some user accounts on both chains
@@ -189,9 +192,11 @@ func TestEIBCFulfillment_EVM(t *testing.T) {
 	require.NoError(t, err)
 
 	// wait until the packet is finalized
-	isFinalized, err := dymension.WaitUntilRollappHeightIsFinalized(ctx, rollapp1.GetChainID(), rollappHeight, 300)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
 	require.NoError(t, err)
-	require.True(t, isFinalized)
 
 	expMmBalanceRollappDenom := transferData.Amount
 	balance, err := dymension.GetBalance(ctx, marketMakerAddr, rollappIBCDenom)
@@ -219,13 +224,19 @@ func TestEIBCFulfillment_EVM(t *testing.T) {
 	fmt.Println("Balance of dymensionUserAddr right after sending eIBC transfer:", balance)
 	require.True(t, balance.Equal(zeroBalance), fmt.Sprintf("Value mismatch. Expected %s, actual %s", zeroBalance, balance))
 
-	// get eIbc event
-	eibcEvents, err := getEIbcEventsWithinBlockRange(ctx, dymension, 30, false)
+	// get the demand order created for the transfer
+	orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	_, err = WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(dymensionUserAddr))
 	require.NoError(t, err)
-	fmt.Println("Event:", eibcEvents[0])
+	demandOrders := RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 1)
+	fmt.Println("Demand order:", demandOrders[0])
+	require.Equal(t, dymensionUserAddr, demandOrders[0].Recipient)
+	require.True(t, demandOrders[0].Fee.AmountOf(rollappIBCDenom).Equal(eibcFee))
+	require.True(t, demandOrders[0].Price.AmountOf(rollappIBCDenom).Equal(transferAmountWithoutFee))
 
 	// fulfill demand order
-	txhash, err := dymension.FullfillDemandOrder(ctx, eibcEvents[0].ID, marketMakerAddr)
+	txhash, err := dymension.FullfillDemandOrder(ctx, demandOrders[0].ID, marketMakerAddr)
 	require.NoError(t, err)
 	fmt.Println(txhash)
 	eibcEvent := getEibcEventFromTx(t, dymension, txhash)
@@ -237,6 +248,10 @@ func TestEIBCFulfillment_EVM(t *testing.T) {
 	err = testutil.WaitForBlocks(ctx, 5, dymension)
 	require.NoError(t, err)
 
+	// the order left the unfulfilled order book
+	RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 0)
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(dymensionUserAddr), ByFulfilled(true))
+
 	// verify funds minus fee were added to receiver's address
 	balance, err = dymension.GetBalance(ctx, dymensionUserAddr, rollappIBCDenom)
 	require.NoError(t, err)
@@ -249,15 +264,19 @@ func TestEIBCFulfillment_EVM(t *testing.T) {
 	expMmBalanceRollappDenom = expMmBalanceRollappDenom.Sub((transferAmountWithoutFee))
 	require.True(t, balance.Equal(expMmBalanceRollappDenom), fmt.Sprintf("Value mismatch. Expected %s, actual %s", expMmBalanceRollappDenom, balance))
 	// wait until packet finalization and verify funds + fee were added to market maker's wallet address
-	isFinalized, err = dymension.WaitUntilRollappHeightIsFinalized(ctx, rollapp1.GetChainID(), rollappHeight, 300)
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
 	require.NoError(t, err)
-	require.True(t, isFinalized)
 	balance, err = dymension.GetBalance(ctx, marketMakerAddr, rollappIBCDenom)
 	require.NoError(t, err)
 	fmt.Println("Balance of marketMakerAddr after packet finalization:", balance)
 	expMmBalanceRollappDenom = expMmBalanceRollappDenom.Add(transferData.Amount)
 	require.True(t, balance.Equal(expMmBalanceRollappDenom), fmt.Sprintf("Value mismatch. Expected %s, actual %s", expMmBalanceRollappDenom, balance))
 
+	// the order is settled together with its packet
+	RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(dymensionUserAddr), ByFulfilled(true))
+
 	t.Cleanup(
 		func() {
 			err := r.StopRelayer(ctx, eRep)
@@ -353,7 +372,7 @@ func TestEIBCFulfillment_Wasm(t *testing.T) {
 
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 	const ibcPath = "ibc-path"
@@ -381,6 +400,8 @@ func TestEIBCFulfillment_Wasm(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	walletAmount := math.NewInt(1_000_000_000_000)
 
 	// Create some user accounts on both chains
@@ -432,9 +453,11 @@ func TestEIBCFulfillment_Wasm(t *testing.T) {
 	require.NoError(t, err)
 
 	// wait until the packet is finalized
-	isFinalized, err := dymension.WaitUntilRollappHeightIsFinalized(ctx, rollapp1.GetChainID(), rollappHeight, 300)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
 	require.NoError(t, err)
-	require.True(t, isFinalized)
 
 	expMmBalanceRollappDenom := transferData.Amount
 	balance, err := dymension.GetBalance(ctx, marketMakerAddr, rollappIBCDenom)
@@ -462,13 +485,19 @@ func TestEIBCFulfillment_Wasm(t *testing.T) {
 	fmt.Println("Balance of dymensionUserAddr right after sending eIBC transfer:", balance)
 	require.True(t, balance.Equal(zeroBalance), fmt.Sprintf("Value mismatch. Expected %s, actual %s", zeroBalance, balance))
 
-	// get eIbc event
-	eibcEvents, err := getEIbcEventsWithinBlockRange(ctx, dymension, 30, false)
+	// get the demand order created for the transfer
+	orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	_, err = WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(dymensionUserAddr))
 	require.NoError(t, err)
-	fmt.Println("Event:", eibcEvents[0])
+	demandOrders := RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 1)
+	fmt.Println("Demand order:", demandOrders[0])
+	require.Equal(t, dymensionUserAddr, demandOrders[0].Recipient)
+	require.True(t, demandOrders[0].Fee.AmountOf(rollappIBCDenom).Equal(eibcFee))
+	require.True(t, demandOrders[0].Price.AmountOf(rollappIBCDenom).Equal(transferAmountWithoutFee))
 
 	// fulfill demand order
-	txhash, err := dymension.FullfillDemandOrder(ctx, eibcEvents[0].ID, marketMakerAddr)
+	txhash, err := dymension.FullfillDemandOrder(ctx, demandOrders[0].ID, marketMakerAddr)
 	require.NoError(t, err)
 	fmt.Println(txhash)
 	eibcEvent := getEibcEventFromTx(t, dymension, txhash)
@@ -480,6 +509,10 @@ func TestEIBCFulfillment_Wasm(t *testing.T) {
 	err = testutil.WaitForBlocks(ctx, 5, dymension)
 	require.NoError(t, err)
 
+	// the order left the unfulfilled order book
+	RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 0)
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(dymensionUserAddr), ByFulfilled(true))
+
 	// verify funds minus fee were added to receiver's address
 	balance, err = dymension.GetBalance(ctx, dymensionUserAddr, rollappIBCDenom)
 	require.NoError(t, err)
@@ -492,15 +525,19 @@ func TestEIBCFulfillment_Wasm(t *testing.T) {
 	expMmBalanceRollappDenom = expMmBalanceRollappDenom.Sub((transferAmountWithoutFee))
 	require.True(t, balance.Equal(expMmBalanceRollappDenom), fmt.Sprintf("Value mismatch. Expected %s, actual %s", expMmBalanceRollappDenom, balance))
 	// wait until packet finalization and verify funds + fee were added to market maker's wallet address
-	isFinalized, err = dymension.WaitUntilRollappHeightIsFinalized(ctx, rollapp1.GetChainID(), rollappHeight, 300)
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
 	require.NoError(t, err)
-	require.True(t, isFinalized)
 	balance, err = dymension.GetBalance(ctx, marketMakerAddr, rollappIBCDenom)
 	require.NoError(t, err)
 	fmt.Println("Balance of marketMakerAddr after packet finalization:", balance)
 	expMmBalanceRollappDenom = expMmBalanceRollappDenom.Add(transferData.Amount)
 	require.True(t, balance.Equal(expMmBalanceRollappDenom), fmt.Sprintf("Value mismatch. Expected %s, actual %s", expMmBalanceRollappDenom, balance))
 
+	// the order is settled together with its packet
+	RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(dymensionUserAddr), ByFulfilled(true))
+
 	t.Cleanup(
 		func() {
 			err := r.StopRelayer(ctx, eRep)
@@ -582,6 +619,17 @@ func getEIbcEventsWithinBlockRange(
 	return eibcEventsArray, nil
 }
 
+// eibcEventByOrderID returns the last eibc event of the demand order, nil if there is none.
+func eibcEventByOrderID(events []dymensiontesting.EibcEvent, id string) *dymensiontesting.EibcEvent {
+	var found *dymensiontesting.EibcEvent
+	for i := range events {
+		if events[i].ID == id {
+			found = &events[i]
+		}
+	}
+	return found
+}
+
 func getEventsOfType(chain *cosmos.CosmosChain, startHeight uint64, endHeight uint64, eventType string, breakOnFirstOccurence bool) ([]blockdb.Event, error) {
 	var eventTypeArray []blockdb.Event
 	shouldReturn := false
@@ -613,5 +661,10 @@ func getEventsOfType(chain *cosmos.CosmosChain, startHeight uint64, endHeight ui
 }
 
 func BuildEIbcMemo(eibcFee math.Int) string {
-	return fmt.Sprintf(`{"eibc": {"fee": "%s"}}`, eibcFee.String())
+	return BuildEIbcMemoFromString(eibcFee.String())
+}
+
+// BuildEIbcMemoFromString builds an eIBC memo with the fee as given, so malformed fees can be sent as well.
+func BuildEIbcMemoFromString(eibcFee string) string {
+	return fmt.Sprintf(`{"eibc": {"fee": "%s"}}`, eibcFee)
 }
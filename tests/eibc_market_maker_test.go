@@ -0,0 +1,544 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// This test case runs a market maker bot next to eIBC transfers with various fees and verifies that it only
+// fulfills the orders its strategy accepts, and that its ledger matches its balance once the packets are finalized
+func TestEIBCMarketMakerBot_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	const BLOCK_FINALITY_PERIOD = 30
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, dymension, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	marketMaker, rollappUser := users[0], users[5]
+	// each order pays its own recipient
+	recipients := []string{users[1].FormattedAddress(), users[2].FormattedAddress(), users[3].FormattedAddress(), users[4].FormattedAddress()}
+
+	marketMakerAddr := marketMaker.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	multiplier := math.NewInt(10)
+
+	eibcFee := transferAmount.Quo(multiplier) // transferAmount * 0.1
+	transferAmountWithoutFee := transferAmount.Sub(eibcFee)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom for urax on Hub
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// market maker needs to have funds on the hub first to be able to fulfill upcoming demand orders
+	transferData := ibc.WalletData{
+		Address: marketMakerAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount.Mul(multiplier),
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	expMmBalance := transferData.Amount
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	// end of preconditions
+
+	// the bot only fulfills orders paying at least 5% of their amount, and for no more than two orders
+	bot := NewMarketMaker(dymension, marketMakerAddr, MarketMakerStrategy{
+		MinFeeRate: sdk.NewDecWithPrec(5, 2),
+		MinBalance: transferAmount,
+		Budgets:    map[string]math.Int{rollappIBCDenom: transferAmountWithoutFee.MulRaw(2)},
+	})
+	err = bot.Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(bot.Stop)
+
+	// the first order pays too low a fee, the next two are fulfilled and the last one is over budget
+	fees := []math.Int{transferAmount.QuoRaw(100), eibcFee, eibcFee, eibcFee}
+	for i, recipient := range recipients {
+		transferData = ibc.WalletData{
+			Address: recipient,
+			Denom:   rollapp1.Config().Denom,
+			Amount:  transferAmount,
+		}
+		_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{Memo: BuildEIbcMemo(fees[i])})
+		require.NoError(t, err)
+		orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		_, err = WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(recipient))
+		cancel()
+		require.NoError(t, err)
+	}
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	decisionCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	ledger, err := bot.WaitForDecisions(decisionCtx, len(recipients))
+	require.NoError(t, err)
+	fmt.Printf("Market maker ledger: %+v\n", ledger)
+	require.Empty(t, ledger.Failed)
+	require.Len(t, ledger.Fills, 2)
+	require.Len(t, ledger.Skipped, 2)
+
+	lowFeeOrder := RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(recipients[0]))[0]
+	require.False(t, lowFeeOrder.IsFulfilled)
+	require.Contains(t, ledger.Skipped, lowFeeOrder.ID)
+	overBudgetOrder := RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(recipients[3]))[0]
+	require.False(t, overBudgetOrder.IsFulfilled)
+	require.Contains(t, ledger.Skipped, overBudgetOrder.ID)
+	for _, recipient := range recipients[1:3] {
+		order := RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(recipient), ByFulfilled(true))[0]
+		testutil.AssertBalance(t, ctx, dymension, recipient, rollappIBCDenom, transferAmountWithoutFee)
+		require.Contains(t, []string{ledger.Fills[0].OrderID, ledger.Fills[1].OrderID}, order.ID)
+	}
+
+	expMmBalance = expMmBalance.Sub(ledger.Spent(rollappIBCDenom))
+	require.True(t, ledger.Spent(rollappIBCDenom).Equal(transferAmountWithoutFee.MulRaw(2)))
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	// nothing is realized before the packets are finalized
+	require.True(t, ledger.PnL(rollappIBCDenom).IsZero())
+
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	bot.Stop()
+	ledger, err = bot.Reconcile(ctx)
+	require.NoError(t, err)
+	for _, fill := range ledger.Fills {
+		require.Equal(t, stateStatusFinalized, fill.Status, "fill of %s was not settled", fill.OrderID)
+	}
+
+	// the market maker earned the fee of both fills, and the skipped orders paid their recipients in full
+	require.True(t, ledger.PnL(rollappIBCDenom).Equal(eibcFee.MulRaw(2)), "unexpected P&L %s", ledger.PnL(rollappIBCDenom))
+	expMmBalance = expMmBalance.Add(transferAmount.MulRaw(2))
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	require.True(t, expMmBalance.Equal(transferAmount.Mul(multiplier).Add(ledger.PnL(rollappIBCDenom))))
+	testutil.AssertBalance(t, ctx, dymension, recipients[0], rollappIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, recipients[3], rollappIBCDenom, transferAmount)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
+
+func TestEIBCMarketMakerBot_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	const BLOCK_FINALITY_PERIOD = 30
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, dymension, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	marketMaker, rollappUser := users[0], users[5]
+	// each order pays its own recipient
+	recipients := []string{users[1].FormattedAddress(), users[2].FormattedAddress(), users[3].FormattedAddress(), users[4].FormattedAddress()}
+
+	marketMakerAddr := marketMaker.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	multiplier := math.NewInt(10)
+
+	eibcFee := transferAmount.Quo(multiplier) // transferAmount * 0.1
+	transferAmountWithoutFee := transferAmount.Sub(eibcFee)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom for urax on Hub
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// market maker needs to have funds on the hub first to be able to fulfill upcoming demand orders
+	transferData := ibc.WalletData{
+		Address: marketMakerAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount.Mul(multiplier),
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	expMmBalance := transferData.Amount
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	// end of preconditions
+
+	// the bot only fulfills orders paying at least 5% of their amount, and for no more than two orders
+	bot := NewMarketMaker(dymension, marketMakerAddr, MarketMakerStrategy{
+		MinFeeRate: sdk.NewDecWithPrec(5, 2),
+		MinBalance: transferAmount,
+		Budgets:    map[string]math.Int{rollappIBCDenom: transferAmountWithoutFee.MulRaw(2)},
+	})
+	err = bot.Start(ctx)
+	require.NoError(t, err)
+	t.Cleanup(bot.Stop)
+
+	// the first order pays too low a fee, the next two are fulfilled and the last one is over budget
+	fees := []math.Int{transferAmount.QuoRaw(100), eibcFee, eibcFee, eibcFee}
+	for i, recipient := range recipients {
+		transferData = ibc.WalletData{
+			Address: recipient,
+			Denom:   rollapp1.Config().Denom,
+			Amount:  transferAmount,
+		}
+		_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{Memo: BuildEIbcMemo(fees[i])})
+		require.NoError(t, err)
+		orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+		_, err = WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(recipient))
+		cancel()
+		require.NoError(t, err)
+	}
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	decisionCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	ledger, err := bot.WaitForDecisions(decisionCtx, len(recipients))
+	require.NoError(t, err)
+	fmt.Printf("Market maker ledger: %+v\n", ledger)
+	require.Empty(t, ledger.Failed)
+	require.Len(t, ledger.Fills, 2)
+	require.Len(t, ledger.Skipped, 2)
+
+	lowFeeOrder := RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(recipients[0]))[0]
+	require.False(t, lowFeeOrder.IsFulfilled)
+	require.Contains(t, ledger.Skipped, lowFeeOrder.ID)
+	overBudgetOrder := RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(recipients[3]))[0]
+	require.False(t, overBudgetOrder.IsFulfilled)
+	require.Contains(t, ledger.Skipped, overBudgetOrder.ID)
+	for _, recipient := range recipients[1:3] {
+		order := RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(recipient), ByFulfilled(true))[0]
+		testutil.AssertBalance(t, ctx, dymension, recipient, rollappIBCDenom, transferAmountWithoutFee)
+		require.Contains(t, []string{ledger.Fills[0].OrderID, ledger.Fills[1].OrderID}, order.ID)
+	}
+
+	expMmBalance = expMmBalance.Sub(ledger.Spent(rollappIBCDenom))
+	require.True(t, ledger.Spent(rollappIBCDenom).Equal(transferAmountWithoutFee.MulRaw(2)))
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	// nothing is realized before the packets are finalized
+	require.True(t, ledger.PnL(rollappIBCDenom).IsZero())
+
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	bot.Stop()
+	ledger, err = bot.Reconcile(ctx)
+	require.NoError(t, err)
+	for _, fill := range ledger.Fills {
+		require.Equal(t, stateStatusFinalized, fill.Status, "fill of %s was not settled", fill.OrderID)
+	}
+
+	// the market maker earned the fee of both fills, and the skipped orders paid their recipients in full
+	require.True(t, ledger.PnL(rollappIBCDenom).Equal(eibcFee.MulRaw(2)), "unexpected P&L %s", ledger.PnL(rollappIBCDenom))
+	expMmBalance = expMmBalance.Add(transferAmount.MulRaw(2))
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	require.True(t, expMmBalance.Equal(transferAmount.Mul(multiplier).Add(ledger.PnL(rollappIBCDenom))))
+	testutil.AssertBalance(t, ctx, dymension, recipients[0], rollappIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, recipients[3], rollappIBCDenom, transferAmount)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
@@ -0,0 +1,766 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/x/params/client/utils"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	eibc "github.com/dymensionxyz/dymension/v3/x/eibc/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fraudulentPacketAck is the error the hub acknowledges pending rollapp packets with when the rollapp is frozen
+const fraudulentPacketAck = "fraudulent packet"
+
+// This test case follows eIBC demand orders, fulfilled and unfulfilled, until their packets are settled.
+// On finalization an unfulfilled order pays the original recipient and a fulfilled one repays the market maker.
+// On a fraud proposal the packets are reverted: the orders are reverted with them, the recipient of an unfulfilled
+// order gets nothing and the market maker loses what it paid for a fulfilled one
+func TestEIBCOrderLifecycle_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// long enough for the fraud proposal to pass before the packets are finalized
+	const BLOCK_FINALITY_PERIOD = 50
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+			ExtraFlags:    extraFlags,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, dymension, dymension, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, marketMaker, rollappUser := users[0], users[1], users[6]
+	// each order pays its own recipient
+	finalizedUnfulfilled, finalizedFulfilled := users[2].FormattedAddress(), users[3].FormattedAddress()
+	revertedUnfulfilled, revertedFulfilled := users[4].FormattedAddress(), users[5].FormattedAddress()
+
+	marketMakerAddr := marketMaker.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	multiplier := math.NewInt(10)
+
+	eibcFee := transferAmount.Quo(multiplier) // transferAmount * 0.1
+	transferAmountWithoutFee := transferAmount.Sub(eibcFee)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom for urax on Hub
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// market maker needs to have funds on the hub first to be able to fulfill upcoming demand orders
+	transferData := ibc.WalletData{
+		Address: marketMakerAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount.Mul(multiplier),
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	expMmBalance := transferData.Amount
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	// end of preconditions
+
+	// Finalization: one order is left unfulfilled and the other is fulfilled by the market maker
+	_, unfulfilledOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, finalizedUnfulfilled, transferAmount, eibcFee)
+	_, fulfilledOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, finalizedFulfilled, transferAmount, eibcFee)
+
+	_, err = dymension.FullfillDemandOrder(ctx, fulfilledOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	expMmBalance = expMmBalance.Sub(transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, finalizedFulfilled, rollappIBCDenom, transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, finalizedUnfulfilled, rollappIBCDenom, math.ZeroInt())
+
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	// the unfulfilled order pays the whole amount to its recipient, the fulfilled one repays the market maker
+	// the price plus the fee, and its recipient keeps what the market maker paid
+	expMmBalance = expMmBalance.Add(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, finalizedUnfulfilled, rollappIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, finalizedFulfilled, rollappIBCDenom, transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+
+	orders := RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(finalizedUnfulfilled))
+	require.Equal(t, unfulfilledOrder.ID, orders[0].ID)
+	require.False(t, orders[0].IsFulfilled)
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(finalizedFulfilled))
+	require.Equal(t, fulfilledOrder.ID, orders[0].ID)
+	require.True(t, orders[0].IsFulfilled)
+
+	// a settled order can no longer be fulfilled, it is not pending anymore
+	res, err := FulfillDemandOrder(ctx, dymension, dymension.FullNodes[0], unfulfilledOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	require.True(t, TxFailedWith(res, eibc.ErrDemandOrderDoesNotExist), "settled order fulfilled: %s", res.RawLog)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, finalizedUnfulfilled, rollappIBCDenom, transferAmount)
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(finalizedUnfulfilled))
+	require.False(t, orders[0].IsFulfilled)
+
+	// Fraud: both packets are still pending when the rollapp is frozen
+	unfulfilledTx, unfulfilledOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, revertedUnfulfilled, transferAmount, eibcFee)
+	fulfilledTx, fulfilledOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, revertedFulfilled, transferAmount, eibcFee)
+
+	_, err = dymension.FullfillDemandOrder(ctx, fulfilledOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	expMmBalance = expMmBalance.Sub(transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, revertedFulfilled, rollappIBCDenom, transferAmountWithoutFee)
+
+	// the fraud height has to be in a state update that is still pending
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	batchCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	fraudBatch, err := stateWatcher.WaitForBatchCovering(batchCtx, rollappHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, fraudBatch.Status)
+
+	keyDir := dymension.GetRollApps()[0].GetSequencerKeyDir()
+	sequencerAddr, err := dymension.AccountKeyBech32WithKeyDir(ctx, "sequencer", keyDir)
+	require.NoError(t, err)
+
+	deployerWhitelistParams := json.RawMessage(fmt.Sprintf(`[{"address":"%s"}]`, sequencerAddr))
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add current dymensionUserAddr to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	dymChannel, err := r.GetChannels(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymChannel), 1)
+
+	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, dymChannel[0].ChannelID, keyDir)
+	require.NoError(t, err)
+
+	dymClients, err := r.GetClients(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymClients), 1)
+
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	err = dymension.SubmitFraudProposal(
+		ctx, dymensionUser.KeyName(),
+		rollapp1.Config().ChainID,
+		fmt.Sprint(rollappHeight),
+		sequencerAddr,
+		dymClients[0].ClientID,
+		"fraud",
+		"fraud",
+		"500000000000"+dymension.Config().Denom,
+	)
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, "2", cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+30, "2", cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "fraud proposal status did not change to passed")
+
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.True(t, rollappParams.Rollapp.Frozen, "rollapp is not frozen")
+
+	revertedHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	// both orders are reverted together with their packets, and none is left pending for the rollapp
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 0, ByRollapp(rollapp1.GetChainID()))
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusReverted, 1, ByRecipient(revertedUnfulfilled))
+	require.Equal(t, unfulfilledOrder.ID, orders[0].ID)
+	require.False(t, orders[0].IsFulfilled)
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusReverted, 1, ByRecipient(revertedFulfilled))
+	require.Equal(t, fulfilledOrder.ID, orders[0].ID)
+	require.True(t, orders[0].IsFulfilled)
+
+	// the hub rejects the reverted packets so the rollapp refunds the sender
+	for _, tx := range []ibc.Tx{unfulfilledTx, fulfilledTx} {
+		ack, found, err := findWriteAcknowledgement(dymension, fraudHubHeight, revertedHubHeight, tx.Packet)
+		require.NoError(t, err)
+		require.True(t, found, "no acknowledgement was written for reverted packet %d", tx.Packet.Sequence)
+		require.Contains(t, ack, fraudulentPacketAck)
+	}
+
+	// a reverted order can no longer be fulfilled, it is not pending anymore
+	res, err = FulfillDemandOrder(ctx, dymension, dymension.FullNodes[0], unfulfilledOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	require.True(t, TxFailedWith(res, eibc.ErrDemandOrderDoesNotExist), "reverted order fulfilled: %s", res.RawLog)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, revertedUnfulfilled, rollappIBCDenom, math.ZeroInt())
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusReverted, 1, ByRecipient(revertedUnfulfilled))
+	require.False(t, orders[0].IsFulfilled)
+
+	err = testutil.WaitForBlocks(ctx, BLOCK_FINALITY_PERIOD, dymension)
+	require.NoError(t, err)
+
+	// the recipient of the unfulfilled order never gets paid, the recipient of the fulfilled one keeps what the
+	// market maker paid, and the market maker is never repaid
+	testutil.AssertBalance(t, ctx, dymension, revertedUnfulfilled, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, revertedFulfilled, rollappIBCDenom, transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+
+	// over both orders the market maker earned one fee and lost one price
+	require.True(t, expMmBalance.Equal(transferData.Amount.Add(eibcFee).Sub(transferAmountWithoutFee)))
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
+
+func TestEIBCOrderLifecycle_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// long enough for the fraud proposal to pass before the packets are finalized
+	const BLOCK_FINALITY_PERIOD = 50
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+			ExtraFlags:    extraFlags,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, dymension, dymension, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, marketMaker, rollappUser := users[0], users[1], users[6]
+	// each order pays its own recipient
+	finalizedUnfulfilled, finalizedFulfilled := users[2].FormattedAddress(), users[3].FormattedAddress()
+	revertedUnfulfilled, revertedFulfilled := users[4].FormattedAddress(), users[5].FormattedAddress()
+
+	marketMakerAddr := marketMaker.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	multiplier := math.NewInt(10)
+
+	eibcFee := transferAmount.Quo(multiplier) // transferAmount * 0.1
+	transferAmountWithoutFee := transferAmount.Sub(eibcFee)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom for urax on Hub
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// market maker needs to have funds on the hub first to be able to fulfill upcoming demand orders
+	transferData := ibc.WalletData{
+		Address: marketMakerAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount.Mul(multiplier),
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	expMmBalance := transferData.Amount
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	// end of preconditions
+
+	// Finalization: one order is left unfulfilled and the other is fulfilled by the market maker
+	_, unfulfilledOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, finalizedUnfulfilled, transferAmount, eibcFee)
+	_, fulfilledOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, finalizedFulfilled, transferAmount, eibcFee)
+
+	_, err = dymension.FullfillDemandOrder(ctx, fulfilledOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	expMmBalance = expMmBalance.Sub(transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, finalizedFulfilled, rollappIBCDenom, transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, finalizedUnfulfilled, rollappIBCDenom, math.ZeroInt())
+
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	// the unfulfilled order pays the whole amount to its recipient, the fulfilled one repays the market maker
+	// the price plus the fee, and its recipient keeps what the market maker paid
+	expMmBalance = expMmBalance.Add(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, finalizedUnfulfilled, rollappIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, finalizedFulfilled, rollappIBCDenom, transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+
+	orders := RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(finalizedUnfulfilled))
+	require.Equal(t, unfulfilledOrder.ID, orders[0].ID)
+	require.False(t, orders[0].IsFulfilled)
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(finalizedFulfilled))
+	require.Equal(t, fulfilledOrder.ID, orders[0].ID)
+	require.True(t, orders[0].IsFulfilled)
+
+	// a settled order can no longer be fulfilled, it is not pending anymore
+	res, err := FulfillDemandOrder(ctx, dymension, dymension.FullNodes[0], unfulfilledOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	require.True(t, TxFailedWith(res, eibc.ErrDemandOrderDoesNotExist), "settled order fulfilled: %s", res.RawLog)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, finalizedUnfulfilled, rollappIBCDenom, transferAmount)
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusFinalized, 1, ByRecipient(finalizedUnfulfilled))
+	require.False(t, orders[0].IsFulfilled)
+
+	// Fraud: both packets are still pending when the rollapp is frozen
+	unfulfilledTx, unfulfilledOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, revertedUnfulfilled, transferAmount, eibcFee)
+	fulfilledTx, fulfilledOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, revertedFulfilled, transferAmount, eibcFee)
+
+	_, err = dymension.FullfillDemandOrder(ctx, fulfilledOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	expMmBalance = expMmBalance.Sub(transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, revertedFulfilled, rollappIBCDenom, transferAmountWithoutFee)
+
+	// the fraud height has to be in a state update that is still pending
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	batchCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	fraudBatch, err := stateWatcher.WaitForBatchCovering(batchCtx, rollappHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, fraudBatch.Status)
+
+	keyDir := dymension.GetRollApps()[0].GetSequencerKeyDir()
+	sequencerAddr, err := dymension.AccountKeyBech32WithKeyDir(ctx, "sequencer", keyDir)
+	require.NoError(t, err)
+
+	deployerWhitelistParams := json.RawMessage(fmt.Sprintf(`[{"address":"%s"}]`, sequencerAddr))
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add current dymensionUserAddr to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	dymChannel, err := r.GetChannels(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymChannel), 1)
+
+	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, dymChannel[0].ChannelID, keyDir)
+	require.NoError(t, err)
+
+	dymClients, err := r.GetClients(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymClients), 1)
+
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	err = dymension.SubmitFraudProposal(
+		ctx, dymensionUser.KeyName(),
+		rollapp1.Config().ChainID,
+		fmt.Sprint(rollappHeight),
+		sequencerAddr,
+		dymClients[0].ClientID,
+		"fraud",
+		"fraud",
+		"500000000000"+dymension.Config().Denom,
+	)
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, "2", cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+30, "2", cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "fraud proposal status did not change to passed")
+
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.True(t, rollappParams.Rollapp.Frozen, "rollapp is not frozen")
+
+	revertedHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	// both orders are reverted together with their packets, and none is left pending for the rollapp
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 0, ByRollapp(rollapp1.GetChainID()))
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusReverted, 1, ByRecipient(revertedUnfulfilled))
+	require.Equal(t, unfulfilledOrder.ID, orders[0].ID)
+	require.False(t, orders[0].IsFulfilled)
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusReverted, 1, ByRecipient(revertedFulfilled))
+	require.Equal(t, fulfilledOrder.ID, orders[0].ID)
+	require.True(t, orders[0].IsFulfilled)
+
+	// the hub rejects the reverted packets so the rollapp refunds the sender
+	for _, tx := range []ibc.Tx{unfulfilledTx, fulfilledTx} {
+		ack, found, err := findWriteAcknowledgement(dymension, fraudHubHeight, revertedHubHeight, tx.Packet)
+		require.NoError(t, err)
+		require.True(t, found, "no acknowledgement was written for reverted packet %d", tx.Packet.Sequence)
+		require.Contains(t, ack, fraudulentPacketAck)
+	}
+
+	// a reverted order can no longer be fulfilled, it is not pending anymore
+	res, err = FulfillDemandOrder(ctx, dymension, dymension.FullNodes[0], unfulfilledOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	require.True(t, TxFailedWith(res, eibc.ErrDemandOrderDoesNotExist), "reverted order fulfilled: %s", res.RawLog)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, revertedUnfulfilled, rollappIBCDenom, math.ZeroInt())
+	orders = RequireDemandOrders(t, ctx, dymension, stateStatusReverted, 1, ByRecipient(revertedUnfulfilled))
+	require.False(t, orders[0].IsFulfilled)
+
+	err = testutil.WaitForBlocks(ctx, BLOCK_FINALITY_PERIOD, dymension)
+	require.NoError(t, err)
+
+	// the recipient of the unfulfilled order never gets paid, the recipient of the fulfilled one keeps what the
+	// market maker paid, and the market maker is never repaid
+	testutil.AssertBalance(t, ctx, dymension, revertedUnfulfilled, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, revertedFulfilled, rollappIBCDenom, transferAmountWithoutFee)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expMmBalance)
+
+	// over both orders the market maker earned one fee and lost one price
+	require.True(t, expMmBalance.Equal(transferData.Amount.Add(eibcFee).Sub(transferAmountWithoutFee)))
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
+
+// sendEIbcTransferForOrder sends amount from the rollapp to the recipient on the hub with an eIBC fee, and waits
+// for the demand order created for it.
+func sendEIbcTransferForOrder(
+	t *testing.T,
+	ctx context.Context,
+	dymension *dym_hub.DymHub,
+	rollapp *cosmos.CosmosChain,
+	channelID, sender, recipient string,
+	amount, fee math.Int,
+) (ibc.Tx, DemandOrder) {
+	transferData := ibc.WalletData{
+		Address: recipient,
+		Denom:   rollapp.Config().Denom,
+		Amount:  amount,
+	}
+	tx, err := rollapp.SendIBCTransfer(ctx, channelID, sender, transferData, ibc.TransferOptions{Memo: BuildEIbcMemo(fee)})
+	require.NoError(t, err)
+
+	orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	orders, err := WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(recipient))
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	require.False(t, orders[0].IsFulfilled)
+	return tx, orders[0]
+}
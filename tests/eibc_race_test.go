@@ -0,0 +1,616 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	eibc "github.com/dymensionxyz/dymension/v3/x/eibc/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// This test case verifies that when several market makers try to fulfill the same demand order at once,
+// exactly one of them wins, the others are rejected, and only the winner is paid after finalization
+func TestEIBCFulfillmentRace_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	const BLOCK_FINALITY_PERIOD = 50
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	// every market maker fulfills from a hub node of its own
+	numHubFullNodes := 2
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains, with three competing market makers on the hub
+	const numMarketMakers = 3
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[numMarketMakers+1]
+	marketMakers := users[1 : numMarketMakers+1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+	marketMakerAddrs := make([]string, numMarketMakers)
+	for i, marketMaker := range marketMakers {
+		marketMakerAddrs[i] = marketMaker.FormattedAddress()
+	}
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount)
+	for _, marketMakerAddr := range marketMakerAddrs {
+		testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, walletAmount)
+	}
+
+	// the keys are created on the first full node, the market makers sending from another node need them too
+	hubNodes := append(cosmos.Nodes{}, dymension.FullNodes...)
+	hubNodes = append(hubNodes, dymension.Validators...)
+	require.GreaterOrEqual(t, len(hubNodes), numMarketMakers)
+	for i, marketMaker := range marketMakers {
+		if i == 0 {
+			continue
+		}
+		err = hubNodes[i].RecoverKey(ctx, marketMaker.KeyName(), marketMaker.Mnemonic())
+		require.NoError(t, err)
+	}
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	multiplier := math.NewInt(10)
+
+	eibcFee := transferAmount.Quo(multiplier) // transferAmount * 0.1
+	transferAmountWithoutFee := transferAmount.Sub(eibcFee)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom for urax on Hub
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// market makers need to have funds on the hub first to be able to fulfill the upcoming demand order
+	var options ibc.TransferOptions
+	for _, marketMakerAddr := range marketMakerAddrs {
+		transferData := ibc.WalletData{
+			Address: marketMakerAddr,
+			Denom:   rollapp1.Config().Denom,
+			Amount:  transferAmount,
+		}
+		_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, options)
+		require.NoError(t, err)
+	}
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	for _, marketMakerAddr := range marketMakerAddrs {
+		testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, transferAmount)
+	}
+	// end of preconditions
+
+	transferData := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+
+	// set eIBC specific memo
+	options.Memo = BuildEIbcMemo(eibcFee)
+
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, options)
+	require.NoError(t, err)
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	_, err = WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(dymensionUserAddr))
+	require.NoError(t, err)
+	demandOrder := RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 1)[0]
+	t.Logf("Demand order: %v", demandOrder)
+
+	// every market maker broadcasts a fulfillment of the same order at the same time, each from its own node
+	results := make([]*sdk.TxResponse, numMarketMakers)
+	fulfillErrs := make([]error, numMarketMakers)
+	var wg sync.WaitGroup
+	for i, marketMaker := range marketMakers {
+		wg.Add(1)
+		go func(i int, keyName string) {
+			defer wg.Done()
+			results[i], fulfillErrs[i] = FulfillDemandOrder(ctx, dymension, hubNodes[i], demandOrder.ID, keyName)
+		}(i, marketMaker.KeyName())
+	}
+	wg.Wait()
+
+	// the fulfillments all pass the checks of the nodes, the winner is the one the hub executed successfully
+	winner := -1
+	for i, res := range results {
+		require.NoError(t, fulfillErrs[i])
+		if res.Code == 0 {
+			if winner != -1 {
+				require.FailNow(t, "order fulfilled twice", "market makers %s and %s both fulfilled the order", marketMakerAddrs[winner], marketMakerAddrs[i])
+			}
+			winner = i
+			continue
+		}
+		t.Logf("Fulfillment by %s rejected: %s", marketMakerAddrs[i], res.RawLog)
+		require.True(t, TxFailedWith(res, eibc.ErrDemandAlreadyFulfilled), "fulfillment by %s rejected for another reason: %s", marketMakerAddrs[i], res.RawLog)
+	}
+	require.NotEqual(t, -1, winner, "no market maker fulfilled the order")
+	winnerAddr := marketMakerAddrs[winner]
+	t.Logf("Order fulfilled by %s in tx %s", winnerAddr, results[winner].TxHash)
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+
+	// the order is fulfilled once, by the winner
+	RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 0)
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(dymensionUserAddr), ByFulfilled(true))
+
+	// only the winner paid the recipient
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmountWithoutFee)
+	for i, marketMakerAddr := range marketMakerAddrs {
+		expBalance := transferAmount
+		if i == winner {
+			expBalance = transferAmount.Sub(transferAmountWithoutFee)
+		}
+		testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expBalance)
+	}
+
+	// wait until packet finalization and verify only the winner was paid the funds + fee
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmountWithoutFee)
+	for i, marketMakerAddr := range marketMakerAddrs {
+		expBalance := transferAmount
+		if i == winner {
+			expBalance = transferAmount.Add(eibcFee)
+		}
+		testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expBalance)
+	}
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
+
+func TestEIBCFulfillmentRace_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	const BLOCK_FINALITY_PERIOD = 50
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	// every market maker fulfills from a hub node of its own
+	numHubFullNodes := 2
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains, with three competing market makers on the hub
+	const numMarketMakers = 3
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[numMarketMakers+1]
+	marketMakers := users[1 : numMarketMakers+1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+	marketMakerAddrs := make([]string, numMarketMakers)
+	for i, marketMaker := range marketMakers {
+		marketMakerAddrs[i] = marketMaker.FormattedAddress()
+	}
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount)
+	for _, marketMakerAddr := range marketMakerAddrs {
+		testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, walletAmount)
+	}
+
+	// the keys are created on the first full node, the market makers sending from another node need them too
+	hubNodes := append(cosmos.Nodes{}, dymension.FullNodes...)
+	hubNodes = append(hubNodes, dymension.Validators...)
+	require.GreaterOrEqual(t, len(hubNodes), numMarketMakers)
+	for i, marketMaker := range marketMakers {
+		if i == 0 {
+			continue
+		}
+		err = hubNodes[i].RecoverKey(ctx, marketMaker.KeyName(), marketMaker.Mnemonic())
+		require.NoError(t, err)
+	}
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	multiplier := math.NewInt(10)
+
+	eibcFee := transferAmount.Quo(multiplier) // transferAmount * 0.1
+	transferAmountWithoutFee := transferAmount.Sub(eibcFee)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom for urax on Hub
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// market makers need to have funds on the hub first to be able to fulfill the upcoming demand order
+	var options ibc.TransferOptions
+	for _, marketMakerAddr := range marketMakerAddrs {
+		transferData := ibc.WalletData{
+			Address: marketMakerAddr,
+			Denom:   rollapp1.Config().Denom,
+			Amount:  transferAmount,
+		}
+		_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, options)
+		require.NoError(t, err)
+	}
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	for _, marketMakerAddr := range marketMakerAddrs {
+		testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, transferAmount)
+	}
+	// end of preconditions
+
+	transferData := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+
+	// set eIBC specific memo
+	options.Memo = BuildEIbcMemo(eibcFee)
+
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, options)
+	require.NoError(t, err)
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	orderCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	_, err = WaitForDemandOrders(orderCtx, dymension, stateStatusPending, 1, ByRecipient(dymensionUserAddr))
+	require.NoError(t, err)
+	demandOrder := RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 1)[0]
+	t.Logf("Demand order: %v", demandOrder)
+
+	// every market maker broadcasts a fulfillment of the same order at the same time, each from its own node
+	results := make([]*sdk.TxResponse, numMarketMakers)
+	fulfillErrs := make([]error, numMarketMakers)
+	var wg sync.WaitGroup
+	for i, marketMaker := range marketMakers {
+		wg.Add(1)
+		go func(i int, keyName string) {
+			defer wg.Done()
+			results[i], fulfillErrs[i] = FulfillDemandOrder(ctx, dymension, hubNodes[i], demandOrder.ID, keyName)
+		}(i, marketMaker.KeyName())
+	}
+	wg.Wait()
+
+	// the fulfillments all pass the checks of the nodes, the winner is the one the hub executed successfully
+	winner := -1
+	for i, res := range results {
+		require.NoError(t, fulfillErrs[i])
+		if res.Code == 0 {
+			if winner != -1 {
+				require.FailNow(t, "order fulfilled twice", "market makers %s and %s both fulfilled the order", marketMakerAddrs[winner], marketMakerAddrs[i])
+			}
+			winner = i
+			continue
+		}
+		t.Logf("Fulfillment by %s rejected: %s", marketMakerAddrs[i], res.RawLog)
+		require.True(t, TxFailedWith(res, eibc.ErrDemandAlreadyFulfilled), "fulfillment by %s rejected for another reason: %s", marketMakerAddrs[i], res.RawLog)
+	}
+	require.NotEqual(t, -1, winner, "no market maker fulfilled the order")
+	winnerAddr := marketMakerAddrs[winner]
+	t.Logf("Order fulfilled by %s in tx %s", winnerAddr, results[winner].TxHash)
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+
+	// the order is fulfilled once, by the winner
+	RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 0)
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 1, ByRecipient(dymensionUserAddr), ByFulfilled(true))
+
+	// only the winner paid the recipient
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmountWithoutFee)
+	for i, marketMakerAddr := range marketMakerAddrs {
+		expBalance := transferAmount
+		if i == winner {
+			expBalance = transferAmount.Sub(transferAmountWithoutFee)
+		}
+		testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expBalance)
+	}
+
+	// wait until packet finalization and verify only the winner was paid the funds + fee
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmountWithoutFee)
+	for i, marketMakerAddr := range marketMakerAddrs {
+		expBalance := transferAmount
+		if i == winner {
+			expBalance = transferAmount.Add(eibcFee)
+		}
+		testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, rollappIBCDenom, expBalance)
+	}
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
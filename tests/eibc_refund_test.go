@@ -0,0 +1,568 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// This test case verifies the refunds of hub -> rollapp transfers which fail on the rollapp. An error
+// acknowledgement is held by the hub and refunds the sender in full once the rollapp height is finalized. A
+// timeout creates a demand order for the refund on the hub, that a market maker can fulfill, and the market
+// maker collects the fee once the rollapp height is finalized
+func TestEIBCRefundDemandOrders_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	const BLOCK_FINALITY_PERIOD = 30
+	// the timeout refund fee is taken from the eibc params, 10% of the refunded amount
+	const REFUND_FEE_MULTIPLIER = "0.1"
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+		cosmos.GenesisKV{
+			Key:   "app_state.eibc.params.timeout_fee",
+			Value: REFUND_FEE_MULTIPLIER,
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, marketMaker, rollappUser := users[0], users[1], users[2]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	marketMakerAddr := marketMaker.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	multiplier := math.NewInt(10)
+
+	refundFee := transferAmount.Quo(multiplier) // transferAmount * 0.1
+	refundPrice := transferAmount.Sub(refundFee)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, rollapp1.Config().ChainID, dymension.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	expDymUserBalance := walletAmount
+	expMmBalance := walletAmount
+
+	// Error acknowledgement: the rollapp rejects the transfer since the receiver is not a valid address
+	transferData := ibc.WalletData{
+		Address: "invalid-receiver",
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	errAckTx, err := dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	expDymUserBalance = expDymUserBalance.Sub(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	// the error ack is held by the hub until finalization, without a demand order: the hub only creates refund
+	// demand orders for timeouts
+	ackCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	errAck, err := WaitForRollappPacket(ackCtx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, errAckTx.Packet, stateStatusPending)
+	require.NoError(t, err)
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 0, ByRollapp(rollapp1.GetChainID()))
+
+	// the refund is not credited before finalization
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	// once the rollapp height of the error ack is finalized the sender is refunded in full
+	RequirePacketReleasedAtFinalization(t, ctx, dymension, *errAck)
+	expDymUserBalance = expDymUserBalance.Add(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, expMmBalance)
+
+	// Timeout: stop the relayer so the transfer expires before it reaches the rollapp
+	err = r.StopRelayer(ctx, eRep)
+	require.NoError(t, err, "an error occurred while stopping the relayer")
+
+	transferData = ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	options := ibc.TransferOptions{
+		Timeout: &ibc.IBCTimeout{
+			NanoSeconds: 1000000, // 1 ms - this will cause the transfer to timeout before it is picked by a relayer
+		},
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, options)
+	require.NoError(t, err)
+	expDymUserBalance = expDymUserBalance.Sub(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	// According to delayedack module, we need the rollapp to have finalizedHeight > ibcClientLatestHeight
+	// in order to trigger ibc timeout or else it will trigger callback
+	err = testutil.WaitForBlocks(ctx, 5, rollapp1)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// the demand order is created when the timeout is relayed
+	eibcEvents, err := getEIbcEventsWithinBlockRange(ctx, dymension, 30, false)
+	require.NoError(t, err)
+	timeoutOrder := RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 1)[0]
+	t.Logf("Timeout refund demand order: %v", timeoutOrder)
+	require.Equal(t, dymensionUserAddr, timeoutOrder.Recipient)
+	require.True(t, timeoutOrder.Price.AmountOf(dymension.Config().Denom).Equal(refundPrice))
+	require.True(t, timeoutOrder.Fee.AmountOf(dymension.Config().Denom).Equal(refundFee))
+	require.Equal(t, stateStatusPending, timeoutOrder.PacketStatus)
+	timeoutEvent := eibcEventByOrderID(eibcEvents, timeoutOrder.ID)
+	require.NotNil(t, timeoutEvent, "no eibc event for demand order %s", timeoutOrder.ID)
+	require.Equal(t, refundPrice.String()+dymension.Config().Denom, timeoutEvent.Price)
+	require.Equal(t, refundFee.String()+dymension.Config().Denom, timeoutEvent.Fee)
+	require.False(t, timeoutEvent.IsFulfilled)
+
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	txhash, err := dymension.FullfillDemandOrder(ctx, timeoutOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	eibcEvent := getEibcEventFromTx(t, dymension, txhash)
+	require.NotNil(t, eibcEvent)
+	require.True(t, eibcEvent.IsFulfilled)
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+
+	expDymUserBalance = expDymUserBalance.Add(refundPrice)
+	expMmBalance = expMmBalance.Sub(refundPrice)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, expMmBalance)
+
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	expMmBalance = expMmBalance.Add(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	// over both refunds the sender paid the fee of the timeout refund only, and the market maker earned it
+	require.True(t, expDymUserBalance.Equal(walletAmount.Sub(refundFee)))
+	require.True(t, expMmBalance.Equal(walletAmount.Add(refundFee)))
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
+
+func TestEIBCRefundDemandOrders_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	const BLOCK_FINALITY_PERIOD = 30
+	// the timeout refund fee is taken from the eibc params, 10% of the refunded amount
+	const REFUND_FEE_MULTIPLIER = "0.1"
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+		cosmos.GenesisKV{
+			Key:   "app_state.eibc.params.timeout_fee",
+			Value: REFUND_FEE_MULTIPLIER,
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, marketMaker, rollappUser := users[0], users[1], users[2]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	marketMakerAddr := marketMaker.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	multiplier := math.NewInt(10)
+
+	refundFee := transferAmount.Quo(multiplier) // transferAmount * 0.1
+	refundPrice := transferAmount.Sub(refundFee)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, rollapp1.Config().ChainID, dymension.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	expDymUserBalance := walletAmount
+	expMmBalance := walletAmount
+
+	// Error acknowledgement: the rollapp rejects the transfer since the receiver is not a valid address
+	transferData := ibc.WalletData{
+		Address: "invalid-receiver",
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	errAckTx, err := dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	expDymUserBalance = expDymUserBalance.Sub(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	// the error ack is held by the hub until finalization, without a demand order: the hub only creates refund
+	// demand orders for timeouts
+	ackCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	errAck, err := WaitForRollappPacket(ackCtx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, errAckTx.Packet, stateStatusPending)
+	require.NoError(t, err)
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 0, ByRollapp(rollapp1.GetChainID()))
+
+	// the refund is not credited before finalization
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	// once the rollapp height of the error ack is finalized the sender is refunded in full
+	RequirePacketReleasedAtFinalization(t, ctx, dymension, *errAck)
+	expDymUserBalance = expDymUserBalance.Add(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, expMmBalance)
+
+	// Timeout: stop the relayer so the transfer expires before it reaches the rollapp
+	err = r.StopRelayer(ctx, eRep)
+	require.NoError(t, err, "an error occurred while stopping the relayer")
+
+	transferData = ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	options := ibc.TransferOptions{
+		Timeout: &ibc.IBCTimeout{
+			NanoSeconds: 1000000, // 1 ms - this will cause the transfer to timeout before it is picked by a relayer
+		},
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, options)
+	require.NoError(t, err)
+	expDymUserBalance = expDymUserBalance.Sub(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	// According to delayedack module, we need the rollapp to have finalizedHeight > ibcClientLatestHeight
+	// in order to trigger ibc timeout or else it will trigger callback
+	err = testutil.WaitForBlocks(ctx, 5, rollapp1)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// the demand order is created when the timeout is relayed
+	eibcEvents, err := getEIbcEventsWithinBlockRange(ctx, dymension, 30, false)
+	require.NoError(t, err)
+	timeoutOrder := RequireUnfulfilledOrders(t, ctx, dymension, rollapp1.GetChainID(), 1)[0]
+	t.Logf("Timeout refund demand order: %v", timeoutOrder)
+	require.Equal(t, dymensionUserAddr, timeoutOrder.Recipient)
+	require.True(t, timeoutOrder.Price.AmountOf(dymension.Config().Denom).Equal(refundPrice))
+	require.True(t, timeoutOrder.Fee.AmountOf(dymension.Config().Denom).Equal(refundFee))
+	require.Equal(t, stateStatusPending, timeoutOrder.PacketStatus)
+	timeoutEvent := eibcEventByOrderID(eibcEvents, timeoutOrder.ID)
+	require.NotNil(t, timeoutEvent, "no eibc event for demand order %s", timeoutOrder.ID)
+	require.Equal(t, refundPrice.String()+dymension.Config().Denom, timeoutEvent.Price)
+	require.Equal(t, refundFee.String()+dymension.Config().Denom, timeoutEvent.Fee)
+	require.False(t, timeoutEvent.IsFulfilled)
+
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	txhash, err := dymension.FullfillDemandOrder(ctx, timeoutOrder.ID, marketMakerAddr)
+	require.NoError(t, err)
+	eibcEvent := getEibcEventFromTx(t, dymension, txhash)
+	require.NotNil(t, eibcEvent)
+	require.True(t, eibcEvent.IsFulfilled)
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+
+	expDymUserBalance = expDymUserBalance.Add(refundPrice)
+	expMmBalance = expMmBalance.Sub(refundPrice)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, expMmBalance)
+
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	expMmBalance = expMmBalance.Add(transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, marketMakerAddr, dymension.Config().Denom, expMmBalance)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, expDymUserBalance)
+
+	// over both refunds the sender paid the fee of the timeout refund only, and the market maker earned it
+	require.True(t, expDymUserBalance.Equal(walletAmount.Sub(refundFee)))
+	require.True(t, expMmBalance.Equal(walletAmount.Add(refundFee)))
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+)
+
+// EscrowSnapshot pairs the amount of a token escrowed on the source end of a transfer channel with the supply of
+// the vouchers minted for it on the counterparty end. ICS-20 keeps both equal as long as every packet sent over
+// the channel is either received or refunded.
+type EscrowSnapshot struct {
+	Denom         string
+	Escrowed      math.Int
+	VoucherSupply math.Int
+}
+
+// TakeEscrowSnapshot reads the escrow of denom on the channel of the source chain and the supply of its vouchers on
+// the counterparty chain.
+func TakeEscrowSnapshot(ctx context.Context, source *cosmos.CosmosChain, channel ibc.ChannelOutput, counterparty *cosmos.CosmosChain, denom string) (EscrowSnapshot, error) {
+	escrowed, err := QueryEscrowBalance(ctx, source, channel.PortID, channel.ChannelID, denom)
+	if err != nil {
+		return EscrowSnapshot{}, err
+	}
+
+	voucherDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, denom)
+	supply, err := QueryTotalSupply(ctx, counterparty, transfertypes.ParseDenomTrace(voucherDenom).IBCDenom())
+	if err != nil {
+		return EscrowSnapshot{}, err
+	}
+
+	return EscrowSnapshot{Denom: denom, Escrowed: escrowed, VoucherSupply: supply}, nil
+}
+
+// Sub returns the change from an earlier snapshot of the same denom.
+func (s EscrowSnapshot) Sub(earlier EscrowSnapshot) EscrowSnapshot {
+	return EscrowSnapshot{
+		Denom:         s.Denom,
+		Escrowed:      s.Escrowed.Sub(earlier.Escrowed),
+		VoucherSupply: s.VoucherSupply.Sub(earlier.VoucherSupply),
+	}
+}
+
+// Consistent reports whether the escrowed amount matches the voucher supply.
+func (s EscrowSnapshot) Consistent() bool {
+	return s.Escrowed.Equal(s.VoucherSupply)
+}
+
+func (s EscrowSnapshot) String() string {
+	return fmt.Sprintf("%s escrowed %s, voucher supply %s", s.Denom, s.Escrowed, s.VoucherSupply)
+}
+
+// QueryEscrowBalance returns the balance of denom held by the escrow account of a transfer channel.
+func QueryEscrowBalance(ctx context.Context, chain *cosmos.CosmosChain, portID, channelID, denom string) (math.Int, error) {
+	escrowAddr, err := chain.GetNode().QueryEscrowAddress(ctx, portID, channelID)
+	if err != nil {
+		return math.Int{}, fmt.Errorf("failed to query escrow address of %s/%s: %w", portID, channelID, err)
+	}
+	balance, err := chain.GetBalance(ctx, escrowAddr, denom)
+	if err != nil {
+		return math.Int{}, fmt.Errorf("failed to query escrow balance of %s/%s: %w", portID, channelID, err)
+	}
+	return balance, nil
+}
+
+// QueryTotalSupply returns the total supply of denom on the chain.
+func QueryTotalSupply(ctx context.Context, chain *cosmos.CosmosChain, denom string) (math.Int, error) {
+	stdout, _, err := chain.GetNode().ExecQuery(ctx, "bank", "total", "--denom", denom)
+	if err != nil {
+		return math.Int{}, fmt.Errorf("failed to query total supply of %s: %w", denom, err)
+	}
+
+	var res struct {
+		Amount string `json:"amount"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return math.Int{}, fmt.Errorf("failed to unmarshal total supply of %s: %w", denom, err)
+	}
+	if res.Amount == "" {
+		return math.ZeroInt(), nil
+	}
+	supply, ok := math.NewIntFromString(res.Amount)
+	if !ok {
+		return math.Int{}, fmt.Errorf("invalid total supply %q of %s", res.Amount, denom)
+	}
+	return supply, nil
+}
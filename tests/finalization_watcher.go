@@ -0,0 +1,299 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// FinalizationPrediction estimates when a rollapp height becomes final on the hub.
+// A state update is finalized by the hub at CreationHeight + dispute_period_in_blocks.
+type FinalizationPrediction struct {
+	RollappHeight uint64
+	// Batch is the state update that includes RollappHeight, nil if the height was not submitted yet
+	Batch                 *StateUpdate
+	DisputePeriod         uint64
+	HubHeight             uint64
+	FinalizationHubHeight uint64
+}
+
+// Submitted reports whether the predicted rollapp height is already included in a state update on the hub.
+func (p FinalizationPrediction) Submitted() bool {
+	return p.Batch != nil
+}
+
+// BlocksRemaining returns the number of hub blocks left until the height finalizes,
+// not counting the wait for the batch submission itself.
+func (p FinalizationPrediction) BlocksRemaining() uint64 {
+	if p.FinalizationHubHeight <= p.HubHeight {
+		return 0
+	}
+	return p.FinalizationHubHeight - p.HubHeight
+}
+
+func (p FinalizationPrediction) String() string {
+	if !p.Submitted() {
+		return fmt.Sprintf("rollapp height %d not submitted yet (hub height %d, dispute period %d blocks)",
+			p.RollappHeight, p.HubHeight, p.DisputePeriod)
+	}
+	return fmt.Sprintf("rollapp height %d in state index %d finalizes at hub height %d (hub height %d, dispute period %d blocks)",
+		p.RollappHeight, p.Batch.Index, p.FinalizationHubHeight, p.HubHeight, p.DisputePeriod)
+}
+
+// FinalizationTimeoutError is returned when a rollapp height does not finalize in time.
+type FinalizationTimeoutError struct {
+	RollappID     string
+	RollappHeight uint64
+	// LastFinalized is the latest finalized state update seen, nil if none was finalized yet
+	LastFinalized *StateUpdate
+	// Prediction is the last finalization estimate made for RollappHeight, nil if none could be made
+	Prediction *FinalizationPrediction
+	Err        error
+}
+
+func (e *FinalizationTimeoutError) Error() string {
+	lastFinalized := "no finalized state update"
+	if e.LastFinalized != nil {
+		lastFinalized = "last finalized state update: " + e.LastFinalized.String()
+	}
+	prediction := "no finalization prediction"
+	if e.Prediction != nil {
+		prediction = e.Prediction.String()
+	}
+	return fmt.Sprintf("timed out waiting for rollapp %s height %d to finalize, %s, %s: %v",
+		e.RollappID, e.RollappHeight, lastFinalized, prediction, e.Err)
+}
+
+func (e *FinalizationTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// DisputePeriod returns the dispute_period_in_blocks param of the hub rollapp module.
+// It is queried every time since tests may change it through governance.
+func (w *StateWatcher) DisputePeriod(ctx context.Context) (uint64, error) {
+	stdout, _, err := w.hub.GetNode().ExecQuery(ctx, "rollapp", "params")
+	if err != nil {
+		return 0, fmt.Errorf("failed to query rollapp params: %w", err)
+	}
+
+	var res struct {
+		Params struct {
+			DisputePeriodInBlocks string `json:"dispute_period_in_blocks"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return 0, fmt.Errorf("failed to unmarshal rollapp params: %w", err)
+	}
+
+	disputePeriod, err := strconv.ParseUint(res.Params.DisputePeriodInBlocks, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse dispute period %q: %w", res.Params.DisputePeriodInBlocks, err)
+	}
+	return disputePeriod, nil
+}
+
+// LatestFinalized returns the latest finalized state update of the rollapp.
+// The hub returns an error until the first state update is finalized.
+func (w *StateWatcher) LatestFinalized(ctx context.Context) (*StateUpdate, error) {
+	return w.queryState(ctx, "--finalized")
+}
+
+// PredictFinalization estimates the hub height at which the given rollapp height finalizes.
+func (w *StateWatcher) PredictFinalization(ctx context.Context, rollappHeight uint64) (*FinalizationPrediction, error) {
+	disputePeriod, err := w.DisputePeriod(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hubHeight, err := w.hub.Height(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query hub height: %w", err)
+	}
+
+	prediction := &FinalizationPrediction{
+		RollappHeight: rollappHeight,
+		DisputePeriod: disputePeriod,
+		HubHeight:     hubHeight,
+	}
+
+	latestIndex, err := w.LatestIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := w.StateInfo(ctx, latestIndex)
+	if err != nil {
+		return nil, err
+	}
+	if latest.EndHeight() < rollappHeight {
+		// not submitted yet, the earliest it can finalize is a dispute period from now
+		prediction.FinalizationHubHeight = hubHeight + disputePeriod
+		return prediction, nil
+	}
+
+	batch := latest
+	if !latest.Covers(rollappHeight) {
+		batch, err = w.StateInfoAtHeight(ctx, rollappHeight)
+		if err != nil {
+			return nil, err
+		}
+	}
+	prediction.Batch = batch
+	prediction.FinalizationHubHeight = batch.CreationHeight + disputePeriod
+	return prediction, nil
+}
+
+// FinalizationHubHeight returns the hub height at which the finalized state update at the index was finalized. It
+// searches the state of the hub at past heights, between the creation of the update and now.
+func (w *StateWatcher) FinalizationHubHeight(ctx context.Context, index uint64) (uint64, error) {
+	update, err := w.StateInfo(ctx, index)
+	if err != nil {
+		return 0, err
+	}
+	if update.Status != stateStatusFinalized {
+		return 0, fmt.Errorf("state index %d of %s is %s, not finalized", index, w.rollappID, update.Status)
+	}
+	hubHeight, err := w.hub.Height(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query hub height: %w", err)
+	}
+
+	// the update is pending at its creation height and finalized at the current height
+	pending, finalized := update.CreationHeight, hubHeight
+	for finalized-pending > 1 {
+		height := pending + (finalized-pending)/2
+		past, err := w.fetchState(ctx, "--index", fmt.Sprint(index), "--height", fmt.Sprint(height))
+		if err != nil {
+			return 0, fmt.Errorf("failed to query state index %d at hub height %d: %w", index, height, err)
+		}
+		if past.Status == stateStatusFinalized {
+			finalized = height
+		} else {
+			pending = height
+		}
+	}
+	return finalized, nil
+}
+
+// WaitForHeightFinalized waits until the state update including the given rollapp height is finalized and returns it.
+// Unlike matching block descriptors of the latest finalized batch only, it does not miss heights whose batch was
+// followed by further finalized batches before the first check.
+func (w *StateWatcher) WaitForHeightFinalized(ctx context.Context, rollappHeight uint64) (*StateUpdate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(stateWatcherPollInterval)
+	defer ticker.Stop()
+
+	var (
+		lastErr       error
+		lastFinalized *StateUpdate
+		prediction    *FinalizationPrediction
+	)
+	for {
+		finalized, err := w.LatestFinalized(ctx)
+		if err != nil {
+			lastErr = err
+		} else {
+			lastFinalized = finalized
+			if finalized.EndHeight() >= rollappHeight {
+				if finalized.Covers(rollappHeight) {
+					return finalized, nil
+				}
+				batch, err := w.StateInfoAtHeight(ctx, rollappHeight)
+				if err == nil && batch.Status == stateStatusFinalized {
+					return batch, nil
+				}
+				if err == nil {
+					err = fmt.Errorf("state index %d covering rollapp height %d is %s", batch.Index, rollappHeight, batch.Status)
+				}
+				lastErr = err
+			}
+		}
+
+		if p, err := w.PredictFinalization(ctx, rollappHeight); err == nil {
+			prediction = p
+		}
+
+		select {
+		case <-ctx.Done():
+			err := ctx.Err()
+			if lastErr != nil {
+				err = fmt.Errorf("%w (last query error: %v)", err, lastErr)
+			}
+			return nil, &FinalizationTimeoutError{
+				RollappID:     w.rollappID,
+				RollappHeight: rollappHeight,
+				LastFinalized: lastFinalized,
+				Prediction:    prediction,
+				Err:           err,
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForAnyFinalized waits until at least one state update of the rollapp is finalized and returns the latest one.
+func (w *StateWatcher) WaitForAnyFinalized(ctx context.Context) (*StateUpdate, error) {
+	return w.poll(ctx, "any finalized state update", func(ctx context.Context) (*StateUpdate, bool, error) {
+		finalized, err := w.LatestFinalized(ctx)
+		return finalized, err == nil, err
+	})
+}
+
+// WatchFinalized streams state updates starting at fromIndex, in index order, once each of them is finalized.
+// Reverted state updates are skipped. The channels behave as the ones returned by Watch.
+func (w *StateWatcher) WatchFinalized(ctx context.Context, fromIndex uint64) (<-chan StateUpdate, <-chan error) {
+	finalizedUpdates := make(chan StateUpdate)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(finalizedUpdates)
+
+		ticker := time.NewTicker(stateWatcherPollInterval)
+		defer ticker.Stop()
+
+		next := fromIndex
+		if next == 0 {
+			next = 1
+		}
+		var lastErr error
+		for {
+			finalized, err := w.LatestFinalized(ctx)
+			if err != nil {
+				lastErr = err
+			}
+			// the hub finalizes state updates in index order
+			for ; err == nil && next <= finalized.Index; next++ {
+				var update *StateUpdate
+				update, err = w.StateInfo(ctx, next)
+				if err != nil {
+					lastErr = err
+					break
+				}
+				if update.Status != stateStatusFinalized {
+					continue
+				}
+				select {
+				case finalizedUpdates <- *update:
+				case <-ctx.Done():
+					errc <- w.timeoutError(ctx, fmt.Sprintf("finalized state updates from index %d", fromIndex), lastErr)
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				errc <- w.timeoutError(ctx, fmt.Sprintf("finalized state updates from index %d", fromIndex), lastErr)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return finalizedUpdates, errc
+}
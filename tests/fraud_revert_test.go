@@ -0,0 +1,747 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/x/params/client/utils"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// This test case freezes a rollapp with a fraud proposal while packets sent in both directions are still held by
+// the hub. Every pending packet is reverted: transfers from the rollapp are never credited on the hub and are
+// refunded on the rollapp, including the hub tokens sent back, and their eIBC demand orders are reverted.
+// Afterwards the escrow accounts of both chains still match the vouchers minted on the other side
+func TestFraudRevertPendingPackets_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// long enough for the fraud proposal to pass before the first packet sent is finalized
+	const BLOCK_FINALITY_PERIOD = 80
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+			ExtraFlags:    extraFlags,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, dymension, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, hubSender, rollappUser := users[0], users[1], users[5]
+	// each transfer from the rollapp pays its own recipient
+	plainRecipient, eibcRecipient, hubTokenRecipient := users[2].FormattedAddress(), users[3].FormattedAddress(), users[4].FormattedAddress()
+
+	hubSenderAddr := hubSender.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, hubSenderAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	eibcFee := transferAmount.QuoRaw(10)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	dymChannel, err := r.GetChannels(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymChannel), 1)
+	hubChannel := dymChannel[0]
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom of urax on the hub and of adym on the rollapp
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+
+	// the fraud proposal freezes the light client of the rollapp, which the hub only knows once the genesis event
+	// bound the rollapp to its channel
+	keyDir := dymension.GetRollApps()[0].GetSequencerKeyDir()
+	sequencerAddr, err := dymension.AccountKeyBech32WithKeyDir(ctx, "sequencer", keyDir)
+	require.NoError(t, err)
+
+	deployerWhitelistParams := json.RawMessage(fmt.Sprintf(`[{"address":"%s"}]`, sequencerAddr))
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add current dymensionUserAddr to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, hubChannel.ChannelID, keyDir)
+	require.NoError(t, err)
+
+	dymClients, err := r.GetClients(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymClients), 1)
+
+	rollappEscrowBefore, err := TakeEscrowSnapshot(ctx, rollapp1.CosmosChain, *channel, dymension.CosmosChain, rollapp1.Config().Denom)
+	require.NoError(t, err)
+	hubEscrowBefore, err := TakeEscrowSnapshot(ctx, dymension.CosmosChain, hubChannel, rollapp1.CosmosChain, dymension.Config().Denom)
+	require.NoError(t, err)
+	// end of preconditions
+
+	// hub -> rollapp: the tokens are delivered on the rollapp right away, the hub holds the acknowledgements
+	var hubTxs []ibc.Tx
+	for i := 0; i < 2; i++ {
+		transferData := ibc.WalletData{
+			Address: rollappUserAddr,
+			Denom:   dymension.Config().Denom,
+			Amount:  transferAmount,
+		}
+		tx, err := dymension.SendIBCTransfer(ctx, hubChannel.ChannelID, hubSenderAddr, transferData, ibc.TransferOptions{})
+		require.NoError(t, err)
+		hubTxs = append(hubTxs, tx)
+	}
+
+	for _, tx := range hubTxs {
+		_, err = WaitForRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, tx.Packet, stateStatusPending)
+		require.NoError(t, err)
+	}
+	hubSent := transferAmount.MulRaw(int64(len(hubTxs)))
+	testutil.AssertBalance(t, ctx, dymension, hubSenderAddr, dymension.Config().Denom, walletAmount.Sub(hubSent))
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, hubSent)
+
+	// rollapp -> hub: a plain transfer, an eIBC transfer and hub tokens sent back, all held by the hub
+	transferData := ibc.WalletData{
+		Address: plainRecipient,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	plainTx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	eibcTx, eibcOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, eibcRecipient, transferAmount, eibcFee)
+
+	transferData = ibc.WalletData{
+		Address: hubTokenRecipient,
+		Denom:   hubIBCDenom,
+		Amount:  transferAmount,
+	}
+	hubTokenTx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappTxs := []ibc.Tx{plainTx, eibcTx, hubTokenTx}
+	for _, tx := range rollappTxs {
+		_, err = WaitForRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet, stateStatusPending)
+		require.NoError(t, err)
+	}
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferAmount.MulRaw(2)))
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, hubSent.Sub(transferAmount))
+	testutil.AssertBalance(t, ctx, dymension, plainRecipient, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, eibcRecipient, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, hubTokenRecipient, dymension.Config().Denom, walletAmount)
+
+	// the fraud height has to be in a state update that is still pending
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	batchCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	fraudBatch, err := stateWatcher.WaitForBatchCovering(batchCtx, rollappHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, fraudBatch.Status)
+
+	// every packet is still held when the fraud proposal is submitted
+	for _, tx := range hubTxs {
+		RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, tx.Packet)
+	}
+	for _, tx := range rollappTxs {
+		RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet)
+	}
+
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	err = dymension.SubmitFraudProposal(
+		ctx, dymensionUser.KeyName(),
+		rollapp1.Config().ChainID,
+		fmt.Sprint(rollappHeight),
+		sequencerAddr,
+		dymClients[0].ClientID,
+		"fraud",
+		"fraud",
+		"500000000000"+dymension.Config().Denom,
+	)
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, "2", cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+30, "2", cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "fraud proposal status did not change to passed")
+
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.True(t, rollappParams.Rollapp.Frozen, "rollapp is not frozen")
+
+	revertedHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	// every held packet is reverted and none is left pending for the rollapp
+	pending, err := QueryPendingPackets(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Empty(t, pending, "packets left pending after the fraud: %v", pending)
+	for _, tx := range hubTxs {
+		p, err := FindRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, tx.Packet, 0)
+		require.NoError(t, err)
+		require.Equal(t, stateStatusReverted, p.Status, "packet is not reverted: %s", p)
+	}
+	for _, tx := range rollappTxs {
+		p, err := FindRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet, 0)
+		require.NoError(t, err)
+		require.Equal(t, stateStatusReverted, p.Status, "packet is not reverted: %s", p)
+
+		// the hub rejects the reverted packet so the rollapp refunds the sender
+		ack, found, err := findWriteAcknowledgement(dymension, fraudHubHeight, revertedHubHeight, tx.Packet)
+		require.NoError(t, err)
+		require.True(t, found, "no acknowledgement was written for reverted packet %d", tx.Packet.Sequence)
+		require.Contains(t, ack, fraudulentPacketAck)
+	}
+
+	// the demand order is reverted together with its packet
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 0, ByRollapp(rollapp1.GetChainID()))
+	orders := RequireDemandOrders(t, ctx, dymension, stateStatusReverted, 1, ByRecipient(eibcRecipient))
+	require.Equal(t, eibcOrder.ID, orders[0].ID)
+	require.False(t, orders[0].IsFulfilled)
+
+	// wait past the dispute period so a packet released late would show up in the balances
+	err = testutil.WaitForBlocks(ctx, BLOCK_FINALITY_PERIOD, dymension)
+	require.NoError(t, err)
+
+	// the recipients on the hub never get credited
+	testutil.AssertBalance(t, ctx, dymension, plainRecipient, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, eibcRecipient, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, hubTokenRecipient, dymension.Config().Denom, walletAmount)
+
+	// the rollapp user gets back the rollapp tokens and the hub tokens it sent, while the tokens the hub sent
+	// were delivered before the fraud and stay on the rollapp
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, hubSent)
+	testutil.AssertBalance(t, ctx, dymension, hubSenderAddr, dymension.Config().Denom, walletAmount.Sub(hubSent))
+
+	// the refunds released the rollapp tokens from escrow without minting vouchers on the hub, and the hub tokens
+	// escrowed for the delivered transfers match the vouchers left on the rollapp
+	rollappEscrowAfter, err := TakeEscrowSnapshot(ctx, rollapp1.CosmosChain, *channel, dymension.CosmosChain, rollapp1.Config().Denom)
+	require.NoError(t, err)
+	rollappEscrowDelta := rollappEscrowAfter.Sub(rollappEscrowBefore)
+	require.True(t, rollappEscrowDelta.Escrowed.IsZero(), "rollapp escrow changed: %s", rollappEscrowDelta)
+	require.True(t, rollappEscrowDelta.Consistent(), "rollapp escrow is inconsistent: %s", rollappEscrowDelta)
+
+	hubEscrowAfter, err := TakeEscrowSnapshot(ctx, dymension.CosmosChain, hubChannel, rollapp1.CosmosChain, dymension.Config().Denom)
+	require.NoError(t, err)
+	hubEscrowDelta := hubEscrowAfter.Sub(hubEscrowBefore)
+	require.True(t, hubEscrowDelta.Escrowed.Equal(hubSent), "hub escrow changed by other than %s: %s", hubSent, hubEscrowDelta)
+	require.True(t, hubEscrowDelta.Consistent(), "hub escrow is inconsistent: %s", hubEscrowDelta)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
+
+func TestFraudRevertPendingPackets_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// long enough for the fraud proposal to pass before the first packet sent is finalized
+	const BLOCK_FINALITY_PERIOD = 80
+	modifyGenesisKV := append(
+		dymensionGenesisKV,
+		cosmos.GenesisKV{
+			Key:   "app_state.rollapp.params.dispute_period_in_blocks",
+			Value: fmt.Sprint(BLOCK_FINALITY_PERIOD),
+		},
+	)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(modifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+			ExtraFlags:    extraFlags,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, dymension, dymension, dymension, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, hubSender, rollappUser := users[0], users[1], users[5]
+	// each transfer from the rollapp pays its own recipient
+	plainRecipient, eibcRecipient, hubTokenRecipient := users[2].FormattedAddress(), users[3].FormattedAddress(), users[4].FormattedAddress()
+
+	hubSenderAddr := hubSender.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	// Assert the accounts were funded
+	testutil.AssertBalance(t, ctx, dymension, hubSenderAddr, dymension.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+
+	transferAmount := math.NewInt(1_000_000)
+	eibcFee := transferAmount.QuoRaw(10)
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	dymChannel, err := r.GetChannels(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymChannel), 1)
+	hubChannel := dymChannel[0]
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	// Get the IBC denom of urax on the hub and of adym on the rollapp
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+
+	// the fraud proposal freezes the light client of the rollapp, which the hub only knows once the genesis event
+	// bound the rollapp to its channel
+	keyDir := dymension.GetRollApps()[0].GetSequencerKeyDir()
+	sequencerAddr, err := dymension.AccountKeyBech32WithKeyDir(ctx, "sequencer", keyDir)
+	require.NoError(t, err)
+
+	deployerWhitelistParams := json.RawMessage(fmt.Sprintf(`[{"address":"%s"}]`, sequencerAddr))
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add current dymensionUserAddr to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, hubChannel.ChannelID, keyDir)
+	require.NoError(t, err)
+
+	dymClients, err := r.GetClients(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymClients), 1)
+
+	rollappEscrowBefore, err := TakeEscrowSnapshot(ctx, rollapp1.CosmosChain, *channel, dymension.CosmosChain, rollapp1.Config().Denom)
+	require.NoError(t, err)
+	hubEscrowBefore, err := TakeEscrowSnapshot(ctx, dymension.CosmosChain, hubChannel, rollapp1.CosmosChain, dymension.Config().Denom)
+	require.NoError(t, err)
+	// end of preconditions
+
+	// hub -> rollapp: the tokens are delivered on the rollapp right away, the hub holds the acknowledgements
+	var hubTxs []ibc.Tx
+	for i := 0; i < 2; i++ {
+		transferData := ibc.WalletData{
+			Address: rollappUserAddr,
+			Denom:   dymension.Config().Denom,
+			Amount:  transferAmount,
+		}
+		tx, err := dymension.SendIBCTransfer(ctx, hubChannel.ChannelID, hubSenderAddr, transferData, ibc.TransferOptions{})
+		require.NoError(t, err)
+		hubTxs = append(hubTxs, tx)
+	}
+
+	for _, tx := range hubTxs {
+		_, err = WaitForRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, tx.Packet, stateStatusPending)
+		require.NoError(t, err)
+	}
+	hubSent := transferAmount.MulRaw(int64(len(hubTxs)))
+	testutil.AssertBalance(t, ctx, dymension, hubSenderAddr, dymension.Config().Denom, walletAmount.Sub(hubSent))
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, hubSent)
+
+	// rollapp -> hub: a plain transfer, an eIBC transfer and hub tokens sent back, all held by the hub
+	transferData := ibc.WalletData{
+		Address: plainRecipient,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	plainTx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	eibcTx, eibcOrder := sendEIbcTransferForOrder(t, ctx, dymension, rollapp1.CosmosChain, channel.ChannelID, rollappUserAddr, eibcRecipient, transferAmount, eibcFee)
+
+	transferData = ibc.WalletData{
+		Address: hubTokenRecipient,
+		Denom:   hubIBCDenom,
+		Amount:  transferAmount,
+	}
+	hubTokenTx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappTxs := []ibc.Tx{plainTx, eibcTx, hubTokenTx}
+	for _, tx := range rollappTxs {
+		_, err = WaitForRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet, stateStatusPending)
+		require.NoError(t, err)
+	}
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferAmount.MulRaw(2)))
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, hubSent.Sub(transferAmount))
+	testutil.AssertBalance(t, ctx, dymension, plainRecipient, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, eibcRecipient, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, hubTokenRecipient, dymension.Config().Denom, walletAmount)
+
+	// the fraud height has to be in a state update that is still pending
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	batchCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	fraudBatch, err := stateWatcher.WaitForBatchCovering(batchCtx, rollappHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, fraudBatch.Status)
+
+	// every packet is still held when the fraud proposal is submitted
+	for _, tx := range hubTxs {
+		RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, tx.Packet)
+	}
+	for _, tx := range rollappTxs {
+		RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet)
+	}
+
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	err = dymension.SubmitFraudProposal(
+		ctx, dymensionUser.KeyName(),
+		rollapp1.Config().ChainID,
+		fmt.Sprint(rollappHeight),
+		sequencerAddr,
+		dymClients[0].ClientID,
+		"fraud",
+		"fraud",
+		"500000000000"+dymension.Config().Denom,
+	)
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, "2", cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+30, "2", cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "fraud proposal status did not change to passed")
+
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.True(t, rollappParams.Rollapp.Frozen, "rollapp is not frozen")
+
+	revertedHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	// every held packet is reverted and none is left pending for the rollapp
+	pending, err := QueryPendingPackets(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Empty(t, pending, "packets left pending after the fraud: %v", pending)
+	for _, tx := range hubTxs {
+		p, err := FindRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, tx.Packet, 0)
+		require.NoError(t, err)
+		require.Equal(t, stateStatusReverted, p.Status, "packet is not reverted: %s", p)
+	}
+	for _, tx := range rollappTxs {
+		p, err := FindRollappPacket(ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, tx.Packet, 0)
+		require.NoError(t, err)
+		require.Equal(t, stateStatusReverted, p.Status, "packet is not reverted: %s", p)
+
+		// the hub rejects the reverted packet so the rollapp refunds the sender
+		ack, found, err := findWriteAcknowledgement(dymension, fraudHubHeight, revertedHubHeight, tx.Packet)
+		require.NoError(t, err)
+		require.True(t, found, "no acknowledgement was written for reverted packet %d", tx.Packet.Sequence)
+		require.Contains(t, ack, fraudulentPacketAck)
+	}
+
+	// the demand order is reverted together with its packet
+	RequireDemandOrders(t, ctx, dymension, stateStatusPending, 0, ByRollapp(rollapp1.GetChainID()))
+	orders := RequireDemandOrders(t, ctx, dymension, stateStatusReverted, 1, ByRecipient(eibcRecipient))
+	require.Equal(t, eibcOrder.ID, orders[0].ID)
+	require.False(t, orders[0].IsFulfilled)
+
+	// wait past the dispute period so a packet released late would show up in the balances
+	err = testutil.WaitForBlocks(ctx, BLOCK_FINALITY_PERIOD, dymension)
+	require.NoError(t, err)
+
+	// the recipients on the hub never get credited
+	testutil.AssertBalance(t, ctx, dymension, plainRecipient, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, eibcRecipient, rollappIBCDenom, math.ZeroInt())
+	testutil.AssertBalance(t, ctx, dymension, hubTokenRecipient, dymension.Config().Denom, walletAmount)
+
+	// the rollapp user gets back the rollapp tokens and the hub tokens it sent, while the tokens the hub sent
+	// were delivered before the fraud and stay on the rollapp
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, hubSent)
+	testutil.AssertBalance(t, ctx, dymension, hubSenderAddr, dymension.Config().Denom, walletAmount.Sub(hubSent))
+
+	// the refunds released the rollapp tokens from escrow without minting vouchers on the hub, and the hub tokens
+	// escrowed for the delivered transfers match the vouchers left on the rollapp
+	rollappEscrowAfter, err := TakeEscrowSnapshot(ctx, rollapp1.CosmosChain, *channel, dymension.CosmosChain, rollapp1.Config().Denom)
+	require.NoError(t, err)
+	rollappEscrowDelta := rollappEscrowAfter.Sub(rollappEscrowBefore)
+	require.True(t, rollappEscrowDelta.Escrowed.IsZero(), "rollapp escrow changed: %s", rollappEscrowDelta)
+	require.True(t, rollappEscrowDelta.Consistent(), "rollapp escrow is inconsistent: %s", rollappEscrowDelta)
+
+	hubEscrowAfter, err := TakeEscrowSnapshot(ctx, dymension.CosmosChain, hubChannel, rollapp1.CosmosChain, dymension.Config().Denom)
+	require.NoError(t, err)
+	hubEscrowDelta := hubEscrowAfter.Sub(hubEscrowBefore)
+	require.True(t, hubEscrowDelta.Escrowed.Equal(hubSent), "hub escrow changed by other than %s: %s", hubSent, hubEscrowDelta)
+	require.True(t, hubEscrowDelta.Consistent(), "hub escrow is inconsistent: %s", hubEscrowDelta)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+}
@@ -4,9 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"cosmossdk.io/math"
 	"github.com/cosmos/cosmos-sdk/x/params/client/utils"
@@ -115,7 +115,7 @@ func TestRollAppFreeze_EVM(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
@@ -143,6 +143,8 @@ func TestRollAppFreeze_EVM(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
 	require.NoError(t, err)
 
@@ -212,29 +214,14 @@ func TestRollAppFreeze_EVM(t *testing.T) {
 	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, dymChannel[0].ChannelID, keyDir)
 	require.NoError(t, err)
 
-	oldLatestIndex, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp1.Config().ChainID)
-	require.NoError(t, err)
-
-	// Access the index value
-	index := oldLatestIndex.StateIndex.Index
-	uintIndex, err := strconv.ParseUint(index, 10, 64)
+	// Wait until the rollapp submits a new state update
+	stateWatcher := NewStateWatcher(dymension, rollapp1.Config().ChainID)
+	waitCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	nextBatch, err := stateWatcher.WaitForNextBatch(waitCtx)
 	require.NoError(t, err)
 
-	targetIndex := uintIndex + 1
-
-	// Loop until the latest index updates
-	for {
-		oldLatestIndex, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp1.Config().ChainID)
-		require.NoError(t, err)
-
-		index := oldLatestIndex.StateIndex.Index
-		uintIndex, err := strconv.ParseUint(index, 10, 64)
-
-		require.NoError(t, err)
-		if uintIndex >= targetIndex {
-			break
-		}
-	}
+	targetIndex := nextBatch.Index
 
 	rollappHeight, err := rollapp1.Height(ctx)
 	require.NoError(t, err)
@@ -373,7 +360,7 @@ func TestRollAppFreeze_Wasm(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
@@ -401,6 +388,8 @@ func TestRollAppFreeze_Wasm(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
 	require.NoError(t, err)
 
@@ -482,30 +471,13 @@ func TestRollAppFreeze_Wasm(t *testing.T) {
 	err = testutil.WaitForBlocks(ctx, 1, dymension, rollapp1)
 	require.NoError(t, err)
 
-	oldLatestIndex, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp1.Config().ChainID)
+	// Wait until the rollapp submits a new state update
+	stateWatcher := NewStateWatcher(dymension, rollapp1.Config().ChainID)
+	waitCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForNextBatch(waitCtx)
 	require.NoError(t, err)
 
-	// Access the index value
-	index := oldLatestIndex.StateIndex.Index
-	uintIndex, err := strconv.ParseUint(index, 10, 64)
-	require.NoError(t, err)
-
-	targetIndex := uintIndex + 1
-
-	// Loop until the latest index updates
-	for {
-		oldLatestIndex, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp1.Config().ChainID)
-		require.NoError(t, err)
-
-		index := oldLatestIndex.StateIndex.Index
-		uintIndex, err := strconv.ParseUint(index, 10, 64)
-
-		require.NoError(t, err)
-		if uintIndex >= targetIndex {
-			break
-		}
-	}
-
 	submitFraudStr := "fraud"
 	deposit := "500000000000" + dymension.Config().Denom
 
@@ -668,11 +640,11 @@ func TestOtherRollappNotAffected_EVM(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer1", network)
 
-	s := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	s := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer2", network)
 
@@ -707,6 +679,8 @@ func TestOtherRollappNotAffected_EVM(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID(), rollapp2.GetChainID())
+
 	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1, rollapp2)
 	require.NoError(t, err)
 
@@ -828,32 +802,17 @@ func TestOtherRollappNotAffected_EVM(t *testing.T) {
 	err = testutil.WaitForBlocks(ctx, 1, dymension, rollapp1)
 	require.NoError(t, err)
 
-	oldLatestIndex, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp1.Config().ChainID)
-	require.NoError(t, err)
-
-	// Access the index value
-	index := oldLatestIndex.StateIndex.Index
-	oldUintIndex, err := strconv.ParseUint(index, 10, 64)
-	require.NoError(t, err)
-
-	targetIndex := oldUintIndex + 1
-
 	rollapp2Index, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp2.Config().ChainID)
 	require.NoError(t, err)
 
-	// Loop until the latest index updates
-	for {
-		latestIndex, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp1.Config().ChainID)
-		require.NoError(t, err)
-
-		index := latestIndex.StateIndex.Index
-		uintIndex, err := strconv.ParseUint(index, 10, 64)
-		require.NoError(t, err)
+	// Wait until the rollapp submits a new state update
+	stateWatcher := NewStateWatcher(dymension, rollapp1.Config().ChainID)
+	waitCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	nextBatch, err := stateWatcher.WaitForNextBatch(waitCtx)
+	require.NoError(t, err)
 
-		if uintIndex >= targetIndex {
-			break
-		}
-	}
+	targetIndex := nextBatch.Index
 
 	submitFraudStr := "fraud"
 	deposit := "500000000000" + dymension.Config().Denom
@@ -1104,11 +1063,11 @@ func TestOtherRollappNotAffected_Wasm(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer1", network)
 
-	s := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	s := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer2", network)
 
@@ -1143,6 +1102,8 @@ func TestOtherRollappNotAffected_Wasm(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID(), rollapp2.GetChainID())
+
 	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1, rollapp2)
 	require.NoError(t, err)
 
@@ -1264,32 +1225,17 @@ func TestOtherRollappNotAffected_Wasm(t *testing.T) {
 	err = testutil.WaitForBlocks(ctx, 1, dymension, rollapp1)
 	require.NoError(t, err)
 
-	oldLatestIndex, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp1.Config().ChainID)
-	require.NoError(t, err)
-
-	// Access the index value
-	index := oldLatestIndex.StateIndex.Index
-	oldUintIndex, err := strconv.ParseUint(index, 10, 64)
-	require.NoError(t, err)
-
-	targetIndex := oldUintIndex + 1
-
 	rollapp2Index, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp2.Config().ChainID)
 	require.NoError(t, err)
 
-	// Loop until the latest index updates
-	for {
-		latestIndex, err := dymension.GetNode().QueryLatestStateIndex(ctx, rollapp1.Config().ChainID)
-		require.NoError(t, err)
-
-		index := latestIndex.StateIndex.Index
-		uintIndex, err := strconv.ParseUint(index, 10, 64)
-		require.NoError(t, err)
+	// Wait until the rollapp submits a new state update
+	stateWatcher := NewStateWatcher(dymension, rollapp1.Config().ChainID)
+	waitCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	nextBatch, err := stateWatcher.WaitForNextBatch(waitCtx)
+	require.NoError(t, err)
 
-		if uintIndex >= targetIndex {
-			break
-		}
-	}
+	targetIndex := nextBatch.Index
 
 	submitFraudStr := "fraud"
 	deposit := "500000000000" + dymension.Config().Denom
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+)
+
+// nodeSyncPollInterval is how often a syncing node is polled for its height.
+const nodeSyncPollInterval = 2 * time.Second
+
+// AddRollappFullNodes starts n more full nodes for the rollapp, peering with its running nodes, and returns them.
+// The overrides should be the ones the rollapp was started with, so the full nodes settle on the same hub.
+func AddRollappFullNodes(ctx context.Context, rollapp *cosmos.CosmosChain, configFileOverrides map[string]any, n int) ([]*cosmos.Node, error) {
+	prevCount := len(rollapp.FullNodes)
+	if err := rollapp.AddFullNodes(ctx, configFileOverrides, n); err != nil {
+		return nil, fmt.Errorf("failed to add %d full nodes to %s: %w", n, rollapp.Config().ChainID, err)
+	}
+	return rollapp.FullNodes[prevCount:], nil
+}
+
+// WaitForNodeHeight waits until the node has the block at the given height.
+func WaitForNodeHeight(ctx context.Context, node *cosmos.Node, height uint64) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(nodeSyncPollInterval)
+	defer ticker.Stop()
+
+	var lastHeight uint64
+	var lastErr error
+	for {
+		h, err := node.Height(ctx)
+		if err == nil && h >= height {
+			return nil
+		}
+		if err != nil {
+			lastErr = err
+		} else {
+			lastHeight = h
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for %s to reach height %d, at %d: %w (last error: %v)", node.Name(), height, lastHeight, ctx.Err(), lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// NodeSyncMismatch is a height at which a node differs from the reference node.
+type NodeSyncMismatch struct {
+	Height uint64
+	// Field is what differs, the app hash of the block or a piece of state
+	Field     string
+	Reference string
+	Node      string
+}
+
+// NodeSyncReport is the result of comparing a node with a reference node.
+type NodeSyncReport struct {
+	Reference string
+	Node      string
+	// Compared is the number of heights compared
+	Compared   int
+	Mismatches []NodeSyncMismatch
+}
+
+// Err returns an error listing every mismatch found, or nil if the node matches the reference.
+func (r *NodeSyncReport) Err() error {
+	if len(r.Mismatches) == 0 {
+		return nil
+	}
+	lines := make([]string, len(r.Mismatches))
+	for i, m := range r.Mismatches {
+		lines[i] = fmt.Sprintf("height %d: %s %s on %s, %s on %s", m.Height, m.Field, m.Reference, r.Reference, m.Node, r.Node)
+	}
+	return fmt.Errorf("%d mismatches of %s with %s over %d heights:\n%s",
+		len(r.Mismatches), r.Node, r.Reference, r.Compared, strings.Join(lines, "\n"))
+}
+
+// CompareAppHashes compares the app hash of every block of the node from height fromHeight up to and including
+// toHeight with the block the reference node has at the same height. Both nodes must have the blocks.
+// The app hash of a block is set by the sequencer and copied by every node, so this only checks that the node
+// holds the chain of the sequencer. CompareNodeState checks the state the node computed itself.
+func CompareAppHashes(ctx context.Context, reference, node *cosmos.Node, fromHeight, toHeight uint64) (*NodeSyncReport, error) {
+	if fromHeight == 0 {
+		fromHeight = 1
+	}
+
+	report := &NodeSyncReport{Reference: reference.Name(), Node: node.Name()}
+	for height := int64(fromHeight); height <= int64(toHeight); height++ {
+		want, err := reference.Client.Block(ctx, &height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s block at height %d: %w", reference.Name(), height, err)
+		}
+		got, err := node.Client.Block(ctx, &height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query %s block at height %d: %w", node.Name(), height, err)
+		}
+
+		report.Compared++
+		if !bytes.Equal(want.Block.Header.AppHash, got.Block.Header.AppHash) {
+			report.Mismatches = append(report.Mismatches, NodeSyncMismatch{
+				Height:    uint64(height),
+				Field:     "app hash",
+				Reference: want.Block.Header.AppHash.String(),
+				Node:      got.Block.Header.AppHash.String(),
+			})
+		}
+	}
+	return report, nil
+}
+
+// CompareNodeState compares the state each node computed by executing the blocks up to the height: the total
+// supply and the balances of the addresses, read from the store of each node at that height. A node that executed
+// a block differently from the sequencer answers differently, even though it holds the same blocks.
+func CompareNodeState(ctx context.Context, reference, node *cosmos.Node, height uint64, addresses ...string) (*NodeSyncReport, error) {
+	queries := map[string][]string{"total supply": {"bank", "total"}}
+	fields := []string{"total supply"}
+	for _, address := range addresses {
+		field := "balances of " + address
+		queries[field] = []string{"bank", "balances", address}
+		fields = append(fields, field)
+	}
+
+	report := &NodeSyncReport{Reference: reference.Name(), Node: node.Name(), Compared: 1}
+	for _, field := range fields {
+		want, err := queryNodeCoins(ctx, reference, height, queries[field]...)
+		if err != nil {
+			return nil, err
+		}
+		got, err := queryNodeCoins(ctx, node, height, queries[field]...)
+		if err != nil {
+			return nil, err
+		}
+		if !want.IsEqual(got) {
+			report.Mismatches = append(report.Mismatches, NodeSyncMismatch{
+				Height:    height,
+				Field:     field,
+				Reference: want.String(),
+				Node:      got.String(),
+			})
+		}
+	}
+	return report, nil
+}
+
+// queryNodeCoins runs a bank query listing coins on the node, against its store at the height.
+func queryNodeCoins(ctx context.Context, node *cosmos.Node, height uint64, query ...string) (sdk.Coins, error) {
+	stdout, _, err := node.ExecQuery(ctx, append(query, "--height", fmt.Sprint(height))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s at height %d on %s: %w", strings.Join(query, " "), height, node.Name(), err)
+	}
+
+	var res struct {
+		Supply   sdk.Coins `json:"supply"`
+		Balances sdk.Coins `json:"balances"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s at height %d on %s: %w", strings.Join(query, " "), height, node.Name(), err)
+	}
+	return res.Supply.Add(res.Balances...), nil
+}
+
+// QueryNodeBalance returns the balance of denom of the address as seen by the given node, rather than by the node
+// the chain queries by default.
+func QueryNodeBalance(ctx context.Context, node *cosmos.Node, address, denom string) (math.Int, error) {
+	stdout, _, err := node.ExecQuery(ctx, "bank", "balances", address, "--denom", denom)
+	if err != nil {
+		return math.Int{}, fmt.Errorf("failed to query %s balance of %s on %s: %w", denom, address, node.Name(), err)
+	}
+
+	var res struct {
+		Amount string `json:"amount"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return math.Int{}, fmt.Errorf("failed to unmarshal %s balance of %s on %s: %w", denom, address, node.Name(), err)
+	}
+	if res.Amount == "" {
+		return math.ZeroInt(), nil
+	}
+	balance, ok := math.NewIntFromString(res.Amount)
+	if !ok {
+		return math.Int{}, fmt.Errorf("invalid %s balance %q of %s on %s", denom, res.Amount, address, node.Name())
+	}
+	return balance, nil
+}
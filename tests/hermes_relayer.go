@@ -0,0 +1,825 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/go-bip39"
+	conntypes "github.com/cosmos/ibc-go/v6/modules/core/03-connection/types"
+	commitmenttypes "github.com/cosmos/ibc-go/v6/modules/core/23-commitment/types"
+	"github.com/decentrio/rollup-e2e-testing/dockerutil"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/docker/docker/client"
+	"go.uber.org/zap"
+)
+
+const (
+	hermesBin = "hermes"
+	// hermesHome is bind mounted from /tmp/<relayer name> on the host, like the home of rly
+	hermesHome       = "/home/hermes"
+	hermesConfigFile = "config/config.toml"
+	hermesUidGid     = "1000:1000"
+
+	// rlyConfigFile is patched by the setup after the relayer keys are added, whichever relayer is used
+	rlyConfigFile = "config/config.yaml"
+
+	hermesEthermintPubKey = "/ethermint.crypto.v1.ethsecp256k1.PubKey"
+	hermesEthermintHDPath = "m/44'/60'/0'/0/0"
+)
+
+// hermesRestoredKeyRE extracts the address from the output of hermes keys add.
+var hermesRestoredKeyRE = regexp.MustCompile(`\(([a-z]+1[a-z0-9]+)\)`)
+
+// HermesRelayer runs Hermes in docker. Unlike rly, Hermes keeps every chain in a single config file and has no
+// notion of paths, so the relayer keeps the chains and paths it is given and regenerates the config on change.
+type HermesRelayer struct {
+	*relayer.DockerRelayer
+
+	log         *zap.Logger
+	client      *client.Client
+	testName    string
+	relayerName string
+
+	chains []hermesChainConfig
+	paths  map[string]*hermesPath
+}
+
+type hermesChainConfig struct {
+	cfg               ibc.ChainConfig
+	keyName           string
+	rpcAddr, grpcAddr string
+}
+
+// hermesPath is a path between two chains, with the clients and connection Hermes created for it.
+type hermesPath struct {
+	chainA, chainB hermesPathEnd
+	filter         *ibc.ChannelFilter
+}
+
+type hermesPathEnd struct {
+	chainID      string
+	clientID     string
+	connectionID string
+}
+
+var _ ibc.Relayer = (*HermesRelayer)(nil)
+
+// NewHermesRelayer returns a Hermes relayer running the given image.
+func NewHermesRelayer(log *zap.Logger, testName string, cli *client.Client, relayerName, networkID string, image ibc.DockerImage) (*HermesRelayer, error) {
+	dr, err := relayer.NewDockerRelayer(context.TODO(), log, testName, cli, relayerName, networkID, hermesCommander{},
+		relayer.CustomDockerImage(image.Repository, image.Version, image.UidGid),
+		// the image is built locally, see hermes.Dockerfile
+		relayer.ImagePull(false),
+		relayer.HomeDir(hermesHome),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &HermesRelayer{
+		DockerRelayer: dr,
+		log:           log,
+		client:        cli,
+		testName:      testName,
+		relayerName:   relayerName,
+		paths:         make(map[string]*hermesPath),
+	}, nil
+}
+
+// AddChainConfiguration adds the chain to the config file of Hermes.
+func (r *HermesRelayer) AddChainConfiguration(ctx context.Context, _ ibc.RelayerExecReporter, chainConfig ibc.ChainConfig, keyName, rpcAddr, grpcAddr string) error {
+	r.chains = append(r.chains, hermesChainConfig{cfg: chainConfig, keyName: keyName, rpcAddr: rpcAddr, grpcAddr: grpcAddr})
+	if err := r.writeConfig(ctx); err != nil {
+		return err
+	}
+	// the setup reads the rly config once the keys are added, leave it a file to read
+	return r.writeFile(ctx, rlyConfigFile, nil)
+}
+
+// AddKey creates a key from a new mnemonic. Hermes can only restore keys, so the mnemonic is generated here.
+func (r *HermesRelayer) AddKey(ctx context.Context, rep ibc.RelayerExecReporter, chainID, keyName, coinType string) (ibc.Wallet, error) {
+	chain, err := r.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	entropy, err := bip39.NewEntropy(256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate entropy: %w", err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate mnemonic: %w", err)
+	}
+
+	wallet, err := r.restoreKey(ctx, rep, chain.cfg, keyName, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	// the wallet is looked up by the chain name the setup passes as chain ID, as with rly
+	r.AddWallet(chainID, wallet)
+	return wallet, nil
+}
+
+// RestoreKey restores a key from the mnemonic.
+func (r *HermesRelayer) RestoreKey(ctx context.Context, rep ibc.RelayerExecReporter, cfg ibc.ChainConfig, keyName, mnemonic string) error {
+	wallet, err := r.restoreKey(ctx, rep, cfg, keyName, mnemonic)
+	if err != nil {
+		return err
+	}
+	r.AddWallet(cfg.ChainID, wallet)
+	return nil
+}
+
+func (r *HermesRelayer) restoreKey(ctx context.Context, rep ibc.RelayerExecReporter, cfg ibc.ChainConfig, keyName, mnemonic string) (ibc.Wallet, error) {
+	mnemonicFile := path.Join("keys", cfg.ChainID+".mnemonic")
+	if err := r.writeFile(ctx, mnemonicFile, []byte(mnemonic)); err != nil {
+		return nil, err
+	}
+
+	cmd := hermesCmd(r.HomeDir(), "keys", "add",
+		"--chain", cfg.ChainID,
+		"--key-name", keyName,
+		"--mnemonic-file", path.Join(r.HomeDir(), mnemonicFile),
+		"--overwrite",
+	)
+	if cfg.CoinType == "60" {
+		cmd = append(cmd, "--hd-path", hermesEthermintHDPath)
+	}
+
+	// Restoring a key should be near-instantaneous, so add a 1-minute timeout
+	// to detect if Docker has hung.
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	res := r.Exec(ctx, rep, cmd, nil)
+	if res.Err != nil {
+		return nil, res.Err
+	}
+	match := hermesRestoredKeyRE.FindStringSubmatch(string(res.Stdout))
+	if match == nil {
+		return nil, fmt.Errorf("no address in the output of hermes keys add: %s", res.Stdout)
+	}
+	return &hermesWallet{keyName: keyName, address: match[1], mnemonic: mnemonic}, nil
+}
+
+// GeneratePath records the path, Hermes itself has no paths.
+func (r *HermesRelayer) GeneratePath(_ context.Context, _ ibc.RelayerExecReporter, srcChainID, dstChainID, pathName string) error {
+	r.paths[pathName] = &hermesPath{
+		chainA: hermesPathEnd{chainID: srcChainID},
+		chainB: hermesPathEnd{chainID: dstChainID},
+	}
+	return nil
+}
+
+// UpdatePath sets the channel filter of the path, applied to the channels of its first chain.
+func (r *HermesRelayer) UpdatePath(ctx context.Context, _ ibc.RelayerExecReporter, pathName string, filter ibc.ChannelFilter) error {
+	p, err := r.path(pathName)
+	if err != nil {
+		return err
+	}
+	p.filter = &filter
+	return r.writeConfig(ctx)
+}
+
+// LinkPath creates the clients, the connection and the channel of the path.
+func (r *HermesRelayer) LinkPath(ctx context.Context, rep ibc.RelayerExecReporter, pathName string, channelOpts ibc.CreateChannelOptions, clientOpts ibc.CreateClientOptions) error {
+	if err := r.CreateClients(ctx, rep, pathName, clientOpts); err != nil {
+		return err
+	}
+	if err := r.CreateConnections(ctx, rep, pathName); err != nil {
+		return err
+	}
+	return r.CreateChannel(ctx, rep, pathName, channelOpts)
+}
+
+// CreateClients creates a client of each chain of the path on the other.
+func (r *HermesRelayer) CreateClients(ctx context.Context, rep ibc.RelayerExecReporter, pathName string, opts ibc.CreateClientOptions) error {
+	p, err := r.path(pathName)
+	if err != nil {
+		return err
+	}
+
+	for _, ends := range [][2]*hermesPathEnd{{&p.chainA, &p.chainB}, {&p.chainB, &p.chainA}} {
+		host, reference := ends[0], ends[1]
+		cmd := hermesCmd(r.HomeDir(), "create", "client", "--host-chain", host.chainID, "--reference-chain", reference.chainID)
+		if opts.TrustingPeriod != "" && opts.TrustingPeriod != "0" {
+			cmd = append(cmd, "--trusting-period", opts.TrustingPeriod)
+		}
+
+		var result struct {
+			CreateClient struct {
+				ClientID string `json:"client_id"`
+			} `json:"CreateClient"`
+		}
+		if err := r.execJSON(ctx, rep, cmd, &result); err != nil {
+			return fmt.Errorf("failed to create client of %s on %s: %w", reference.chainID, host.chainID, err)
+		}
+		host.clientID = result.CreateClient.ClientID
+	}
+	return nil
+}
+
+// CreateConnections creates the connection of the path between its clients.
+func (r *HermesRelayer) CreateConnections(ctx context.Context, rep ibc.RelayerExecReporter, pathName string) error {
+	p, err := r.path(pathName)
+	if err != nil {
+		return err
+	}
+
+	cmd := hermesCmd(r.HomeDir(), "create", "connection",
+		"--a-chain", p.chainA.chainID,
+		"--a-client", p.chainA.clientID,
+		"--b-client", p.chainB.clientID,
+	)
+	var result struct {
+		ASide struct {
+			ConnectionID string `json:"connection_id"`
+		} `json:"a_side"`
+		BSide struct {
+			ConnectionID string `json:"connection_id"`
+		} `json:"b_side"`
+	}
+	if err := r.execJSON(ctx, rep, cmd, &result); err != nil {
+		return fmt.Errorf("failed to create connection of path %s: %w", pathName, err)
+	}
+	p.chainA.connectionID = result.ASide.ConnectionID
+	p.chainB.connectionID = result.BSide.ConnectionID
+	return nil
+}
+
+// CreateChannel creates a channel on the connection of the path.
+func (r *HermesRelayer) CreateChannel(ctx context.Context, rep ibc.RelayerExecReporter, pathName string, opts ibc.CreateChannelOptions) error {
+	p, err := r.path(pathName)
+	if err != nil {
+		return err
+	}
+
+	cmd := hermesCmd(r.HomeDir(), "create", "channel",
+		"--a-chain", p.chainA.chainID,
+		"--a-connection", p.chainA.connectionID,
+		"--a-port", opts.SourcePortName,
+		"--b-port", opts.DestPortName,
+		"--order", opts.Order.String(),
+		"--channel-version", opts.Version,
+	)
+	if err := r.execJSON(ctx, rep, cmd, nil); err != nil {
+		return fmt.Errorf("failed to create channel of path %s: %w", pathName, err)
+	}
+	return nil
+}
+
+// UpdateClients updates the clients of both chains of the path.
+func (r *HermesRelayer) UpdateClients(ctx context.Context, rep ibc.RelayerExecReporter, pathName string) error {
+	p, err := r.path(pathName)
+	if err != nil {
+		return err
+	}
+
+	for _, end := range []hermesPathEnd{p.chainA, p.chainB} {
+		cmd := hermesCmd(r.HomeDir(), "update", "client", "--host-chain", end.chainID, "--client", end.clientID)
+		if err := r.execJSON(ctx, rep, cmd, nil); err != nil {
+			return fmt.Errorf("failed to update client %s on %s: %w", end.clientID, end.chainID, err)
+		}
+	}
+	return nil
+}
+
+// Flush relays the pending packets and acknowledgements of the channel of the path, or of all its channels if
+// channelID is empty. The channel ID is the one on either chain of the path.
+func (r *HermesRelayer) Flush(ctx context.Context, rep ibc.RelayerExecReporter, pathName, channelID string) error {
+	p, err := r.path(pathName)
+	if err != nil {
+		return err
+	}
+
+	for _, end := range []hermesPathEnd{p.chainA, p.chainB} {
+		channels, err := r.GetChannels(ctx, rep, end.chainID)
+		if err != nil {
+			return err
+		}
+		for _, channel := range channels {
+			if len(channel.ConnectionHops) == 0 || channel.ConnectionHops[0] != end.connectionID {
+				continue
+			}
+			if channelID != "" && channel.ChannelID != channelID {
+				continue
+			}
+			cmd := hermesCmd(r.HomeDir(), "clear", "packets", "--chain", end.chainID, "--port", channel.PortID, "--channel", channel.ChannelID)
+			if err := r.execJSON(ctx, rep, cmd, nil); err != nil {
+				return fmt.Errorf("failed to clear packets of %s/%s on %s: %w", channel.PortID, channel.ChannelID, end.chainID, err)
+			}
+			if channelID != "" {
+				return nil
+			}
+		}
+		if channelID == "" {
+			// clearing the channels of one end relays both directions
+			return nil
+		}
+	}
+	return fmt.Errorf("channel %s not found on path %s", channelID, pathName)
+}
+
+// execJSON runs the hermes command and decodes its result into result, if not nil.
+func (r *HermesRelayer) execJSON(ctx context.Context, rep ibc.RelayerExecReporter, cmd []string, result any) error {
+	res := r.Exec(ctx, rep, cmd, nil)
+	if res.Err != nil {
+		return res.Err
+	}
+	raw, err := hermesResult(string(res.Stdout))
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(raw, result)
+}
+
+func (r *HermesRelayer) chain(chainID string) (hermesChainConfig, error) {
+	// the setup passes the chain name, which it also uses as key name, where rly expects a chain ID
+	for _, chain := range r.chains {
+		if chain.cfg.ChainID == chainID || chain.keyName == chainID {
+			return chain, nil
+		}
+	}
+	return hermesChainConfig{}, fmt.Errorf("chain %s is not configured on hermes", chainID)
+}
+
+func (r *HermesRelayer) path(pathName string) (*hermesPath, error) {
+	p, found := r.paths[pathName]
+	if !found {
+		return nil, fmt.Errorf("path %s is not configured on hermes", pathName)
+	}
+	return p, nil
+}
+
+// writeConfig writes the config file of Hermes for every chain added so far.
+func (r *HermesRelayer) writeConfig(ctx context.Context) error {
+	config := newHermesConfig()
+	for _, chain := range r.chains {
+		c, err := newHermesChain(chain)
+		if err != nil {
+			return err
+		}
+		for _, p := range r.paths {
+			if p.filter != nil && p.chainA.chainID == c.ID {
+				c.PacketFilter = newHermesPacketFilter(*p.filter)
+			}
+		}
+		config.Chains = append(config.Chains, c)
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(config); err != nil {
+		return fmt.Errorf("failed to encode hermes config: %w", err)
+	}
+	return r.writeFile(ctx, hermesConfigFile, buf.Bytes())
+}
+
+// writeFile writes a file in the home of the relayer.
+func (r *HermesRelayer) writeFile(ctx context.Context, relPath string, content []byte) error {
+	fw := dockerutil.NewFileWriter(r.log, r.client, r.testName)
+	if err := fw.RelayerWriteFile(ctx, "", r.relayerName, relPath, content); err != nil {
+		return fmt.Errorf("failed to write hermes file %s: %w", relPath, err)
+	}
+	return nil
+}
+
+// hermesCmd returns the hermes command with JSON output and the config file in the home of the relayer.
+func hermesCmd(homeDir string, args ...string) []string {
+	return append([]string{hermesBin, "--json", "--config", path.Join(homeDir, hermesConfigFile)}, args...)
+}
+
+// hermesResult returns the result of a hermes command run with --json, printed on the last line after the logs.
+func hermesResult(stdout string) (json.RawMessage, error) {
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		var out struct {
+			Result json.RawMessage `json:"result"`
+			Status string          `json:"status"`
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &out); err != nil || out.Status == "" {
+			continue
+		}
+		if out.Status != "success" {
+			return nil, fmt.Errorf("hermes returned %s: %s", out.Status, out.Result)
+		}
+		return out.Result, nil
+	}
+	return nil, fmt.Errorf("no result in hermes output: %s", stdout)
+}
+
+// hermesState converts a channel or connection state printed by hermes, such as Open, to its proto name.
+func hermesState(state string) string {
+	return "STATE_" + strings.ToUpper(state)
+}
+
+// hermesWallet is a key restored in Hermes.
+type hermesWallet struct {
+	keyName  string
+	address  string
+	mnemonic string
+}
+
+var _ ibc.Wallet = (*hermesWallet)(nil)
+
+func (w *hermesWallet) KeyName() string          { return w.keyName }
+func (w *hermesWallet) FormattedAddress() string { return w.address }
+func (w *hermesWallet) Mnemonic() string         { return w.mnemonic }
+func (w *hermesWallet) Address() []byte          { return []byte(w.address) }
+
+// hermesCommander gives the commands of the operations the HermesRelayer does not override.
+type hermesCommander struct{}
+
+var _ relayer.RelayerCommander = hermesCommander{}
+
+func (hermesCommander) Name() string {
+	return hermesBin
+}
+
+func (hermesCommander) DefaultContainerImage() string {
+	return HermesImage
+}
+
+func (hermesCommander) DefaultContainerVersion() string {
+	return HermesVersion
+}
+
+func (hermesCommander) DockerUser() string {
+	return hermesUidGid
+}
+
+func (hermesCommander) Init(string) []string {
+	return nil
+}
+
+func (hermesCommander) StartRelayer(homeDir string, _ ...string) []string {
+	// hermes relays every chain of its config, the packet filters restrict it to the paths
+	return []string{hermesBin, "--config", path.Join(homeDir, hermesConfigFile), "start"}
+}
+
+func (hermesCommander) GetChannels(chainID, homeDir string) []string {
+	return hermesCmd(homeDir, "query", "channels", "--chain", chainID, "--verbose")
+}
+
+func (hermesCommander) GetConnections(chainID, homeDir string) []string {
+	return hermesCmd(homeDir, "query", "connections", "--chain", chainID, "--verbose")
+}
+
+func (hermesCommander) GetClients(chainID, homeDir string) []string {
+	return hermesCmd(homeDir, "query", "clients", "--host-chain", chainID)
+}
+
+func (hermesCommander) ParseGetChannelsOutput(stdout, _ string) ([]ibc.ChannelOutput, error) {
+	raw, err := hermesResult(stdout)
+	if err != nil {
+		return nil, err
+	}
+	var result []struct {
+		ChannelID  string `json:"channel_id"`
+		PortID     string `json:"port_id"`
+		ChannelEnd struct {
+			ConnectionHops []string `json:"connection_hops"`
+			Ordering       string   `json:"ordering"`
+			State          string   `json:"state"`
+			Version        string   `json:"version"`
+			Remote         struct {
+				ChannelID string `json:"channel_id"`
+				PortID    string `json:"port_id"`
+			} `json:"remote"`
+		} `json:"channel_end"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hermes channels: %w", err)
+	}
+
+	channels := make([]ibc.ChannelOutput, len(result))
+	for i, c := range result {
+		channels[i] = ibc.ChannelOutput{
+			State:    hermesState(c.ChannelEnd.State),
+			Ordering: "ORDER_" + strings.ToUpper(c.ChannelEnd.Ordering),
+			Counterparty: ibc.ChannelCounterparty{
+				PortID:    c.ChannelEnd.Remote.PortID,
+				ChannelID: c.ChannelEnd.Remote.ChannelID,
+			},
+			ConnectionHops: c.ChannelEnd.ConnectionHops,
+			Version:        c.ChannelEnd.Version,
+			PortID:         c.PortID,
+			ChannelID:      c.ChannelID,
+		}
+	}
+	return channels, nil
+}
+
+func (hermesCommander) ParseGetConnectionsOutput(stdout, _ string) (ibc.ConnectionOutputs, error) {
+	raw, err := hermesResult(stdout)
+	if err != nil {
+		return nil, err
+	}
+	var result []struct {
+		ConnectionID  string `json:"connection_id"`
+		ConnectionEnd struct {
+			ClientID     string `json:"client_id"`
+			State        string `json:"state"`
+			Counterparty struct {
+				ClientID     string `json:"client_id"`
+				ConnectionID string `json:"connection_id"`
+				Prefix       string `json:"prefix"`
+			} `json:"counterparty"`
+		} `json:"connection_end"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hermes connections: %w", err)
+	}
+
+	connections := make(ibc.ConnectionOutputs, len(result))
+	for i, c := range result {
+		connections[i] = &ibc.ConnectionOutput{
+			ID:       c.ConnectionID,
+			ClientID: c.ConnectionEnd.ClientID,
+			State:    hermesState(c.ConnectionEnd.State),
+			Counterparty: &conntypes.Counterparty{
+				ClientId:     c.ConnectionEnd.Counterparty.ClientID,
+				ConnectionId: c.ConnectionEnd.Counterparty.ConnectionID,
+				Prefix:       commitmenttypes.NewMerklePrefix([]byte(c.ConnectionEnd.Counterparty.Prefix)),
+			},
+		}
+	}
+	return connections, nil
+}
+
+func (hermesCommander) ParseGetClientsOutput(stdout, _ string) (ibc.ClientOutputs, error) {
+	raw, err := hermesResult(stdout)
+	if err != nil {
+		return nil, err
+	}
+	var result []struct {
+		ChainID  string `json:"chain_id"`
+		ClientID string `json:"client_id"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hermes clients: %w", err)
+	}
+
+	clients := make(ibc.ClientOutputs, len(result))
+	for i, c := range result {
+		clients[i] = &ibc.ClientOutput{
+			ClientID:    c.ClientID,
+			ClientState: ibc.ClientState{ChainID: c.ChainID},
+		}
+	}
+	return clients, nil
+}
+
+func (hermesCommander) CreateWallet(keyName, address, mnemonic string) ibc.Wallet {
+	return &hermesWallet{keyName: keyName, address: address, mnemonic: mnemonic}
+}
+
+// The operations below are overridden by HermesRelayer, since Hermes needs the state it keeps to run them.
+
+func (hermesCommander) ConfigContent(context.Context, ibc.ChainConfig, string, string, string) ([]byte, error) {
+	panic("hermes config is generated by HermesRelayer")
+}
+
+func (hermesCommander) ParseAddKeyOutput(string, string) (ibc.Wallet, error) {
+	panic("hermes keys are added by HermesRelayer")
+}
+
+func (hermesCommander) ParseRestoreKeyOutput(string, string) string {
+	panic("hermes keys are restored by HermesRelayer")
+}
+
+func (hermesCommander) AddChainConfiguration(string, string) []string {
+	panic("hermes chains are configured by HermesRelayer")
+}
+
+func (hermesCommander) AddKey(string, string, string, string) []string {
+	panic("hermes keys are added by HermesRelayer")
+}
+
+func (hermesCommander) RestoreKey(string, string, string, string, string) []string {
+	panic("hermes keys are restored by HermesRelayer")
+}
+
+func (hermesCommander) CreateChannel(string, ibc.CreateChannelOptions, string) []string {
+	panic("hermes channels are created by HermesRelayer")
+}
+
+func (hermesCommander) CreateClients(string, ibc.CreateClientOptions, string) []string {
+	panic("hermes clients are created by HermesRelayer")
+}
+
+func (hermesCommander) CreateConnections(string, string) []string {
+	panic("hermes connections are created by HermesRelayer")
+}
+
+func (hermesCommander) Flush(string, string, string) []string {
+	panic("hermes packets are cleared by HermesRelayer")
+}
+
+func (hermesCommander) GeneratePath(string, string, string, string) []string {
+	panic("hermes paths are kept by HermesRelayer")
+}
+
+func (hermesCommander) UpdatePath(string, string, ibc.ChannelFilter) []string {
+	panic("hermes paths are kept by HermesRelayer")
+}
+
+func (hermesCommander) LinkPath(string, string, ibc.CreateChannelOptions, ibc.CreateClientOptions) []string {
+	panic("hermes paths are linked by HermesRelayer")
+}
+
+func (hermesCommander) UpdateClients(string, string) []string {
+	panic("hermes clients are updated by HermesRelayer")
+}
+
+// hermesConfig is the config file of Hermes, see https://hermes.informal.systems/documentation/configuration
+type hermesConfig struct {
+	Global    hermesGlobal   `toml:"global"`
+	Mode      hermesMode     `toml:"mode"`
+	Rest      hermesEndpoint `toml:"rest"`
+	Telemetry hermesEndpoint `toml:"telemetry"`
+	Chains    []hermesChain  `toml:"chains"`
+}
+
+type hermesGlobal struct {
+	LogLevel string `toml:"log_level"`
+}
+
+type hermesMode struct {
+	Clients     hermesModeClients `toml:"clients"`
+	Connections hermesModeToggle  `toml:"connections"`
+	Channels    hermesModeToggle  `toml:"channels"`
+	Packets     hermesModePackets `toml:"packets"`
+}
+
+type hermesModeClients struct {
+	Enabled      bool `toml:"enabled"`
+	Refresh      bool `toml:"refresh"`
+	Misbehaviour bool `toml:"misbehaviour"`
+}
+
+type hermesModeToggle struct {
+	Enabled bool `toml:"enabled"`
+}
+
+type hermesModePackets struct {
+	Enabled        bool `toml:"enabled"`
+	ClearInterval  int  `toml:"clear_interval"`
+	ClearOnStart   bool `toml:"clear_on_start"`
+	TxConfirmation bool `toml:"tx_confirmation"`
+}
+
+type hermesEndpoint struct {
+	Enabled bool   `toml:"enabled"`
+	Host    string `toml:"host"`
+	Port    int    `toml:"port"`
+}
+
+type hermesChain struct {
+	ID             string               `toml:"id"`
+	Type           string               `toml:"type"`
+	RPCAddr        string               `toml:"rpc_addr"`
+	GRPCAddr       string               `toml:"grpc_addr"`
+	EventSource    hermesEventSource    `toml:"event_source"`
+	RPCTimeout     string               `toml:"rpc_timeout"`
+	AccountPrefix  string               `toml:"account_prefix"`
+	KeyName        string               `toml:"key_name"`
+	AddressType    hermesAddressType    `toml:"address_type"`
+	StorePrefix    string               `toml:"store_prefix"`
+	DefaultGas     uint64               `toml:"default_gas"`
+	MaxGas         uint64               `toml:"max_gas"`
+	GasPrice       hermesGasPrice       `toml:"gas_price"`
+	GasMultiplier  float64              `toml:"gas_multiplier"`
+	MaxMsgNum      int                  `toml:"max_msg_num"`
+	MaxTxSize      int                  `toml:"max_tx_size"`
+	ClockDrift     string               `toml:"clock_drift"`
+	MaxBlockTime   string               `toml:"max_block_time"`
+	TrustingPeriod string               `toml:"trusting_period"`
+	TrustThreshold hermesTrustThreshold `toml:"trust_threshold"`
+	MemoPrefix     string               `toml:"memo_prefix"`
+	PacketFilter   *hermesPacketFilter  `toml:"packet_filter,omitempty"`
+}
+
+type hermesEventSource struct {
+	Mode       string `toml:"mode"`
+	URL        string `toml:"url"`
+	BatchDelay string `toml:"batch_delay"`
+}
+
+type hermesAddressType struct {
+	Derivation string           `toml:"derivation"`
+	ProtoType  *hermesProtoType `toml:"proto_type,omitempty"`
+}
+
+type hermesProtoType struct {
+	PkType string `toml:"pk_type"`
+}
+
+type hermesGasPrice struct {
+	Price float64 `toml:"price"`
+	Denom string  `toml:"denom"`
+}
+
+type hermesTrustThreshold struct {
+	Numerator   string `toml:"numerator"`
+	Denominator string `toml:"denominator"`
+}
+
+type hermesPacketFilter struct {
+	Policy string     `toml:"policy"`
+	List   [][]string `toml:"list"`
+}
+
+func newHermesConfig() hermesConfig {
+	return hermesConfig{
+		Global: hermesGlobal{LogLevel: "info"},
+		Mode: hermesMode{
+			Clients:     hermesModeClients{Enabled: true, Refresh: true, Misbehaviour: true},
+			Connections: hermesModeToggle{Enabled: false},
+			Channels:    hermesModeToggle{Enabled: false},
+			Packets:     hermesModePackets{Enabled: true, ClearInterval: 100, ClearOnStart: true, TxConfirmation: false},
+		},
+		Rest:      hermesEndpoint{Enabled: false, Host: "127.0.0.1", Port: 3000},
+		Telemetry: hermesEndpoint{Enabled: false, Host: "127.0.0.1", Port: 3001},
+	}
+}
+
+func newHermesChain(chain hermesChainConfig) (hermesChain, error) {
+	cfg := chain.cfg
+	gasPrice, err := sdk.ParseDecCoin(cfg.GasPrices)
+	if err != nil {
+		return hermesChain{}, fmt.Errorf("invalid gas prices %q of %s: %w", cfg.GasPrices, cfg.ChainID, err)
+	}
+	price, err := gasPrice.Amount.Float64()
+	if err != nil {
+		return hermesChain{}, fmt.Errorf("invalid gas prices %q of %s: %w", cfg.GasPrices, cfg.ChainID, err)
+	}
+
+	addressType := hermesAddressType{Derivation: "cosmos"}
+	if cfg.CoinType == "60" {
+		addressType = hermesAddressType{Derivation: "ethermint", ProtoType: &hermesProtoType{PkType: hermesEthermintPubKey}}
+	}
+
+	trustingPeriod := cfg.TrustingPeriod
+	if trustingPeriod == "" {
+		trustingPeriod = "112h"
+	}
+
+	rpcAddr := withScheme(chain.rpcAddr, "http")
+	return hermesChain{
+		ID:       cfg.ChainID,
+		Type:     "CosmosSdk",
+		RPCAddr:  rpcAddr,
+		GRPCAddr: withScheme(chain.grpcAddr, "http"),
+		EventSource: hermesEventSource{
+			Mode:       "push",
+			URL:        strings.Replace(rpcAddr, "http", "ws", 1) + "/websocket",
+			BatchDelay: "500ms",
+		},
+		RPCTimeout:     "10s",
+		AccountPrefix:  cfg.Bech32Prefix,
+		KeyName:        chain.keyName,
+		AddressType:    addressType,
+		StorePrefix:    "ibc",
+		DefaultGas:     100_000,
+		MaxGas:         4_000_000,
+		GasPrice:       hermesGasPrice{Price: price, Denom: gasPrice.Denom},
+		GasMultiplier:  max(cfg.GasAdjustment, 1.1),
+		MaxMsgNum:      30,
+		MaxTxSize:      2_097_152,
+		ClockDrift:     "5s",
+		MaxBlockTime:   "30s",
+		TrustingPeriod: trustingPeriod,
+		TrustThreshold: hermesTrustThreshold{Numerator: "1", Denominator: "3"},
+	}, nil
+}
+
+func newHermesPacketFilter(filter ibc.ChannelFilter) *hermesPacketFilter {
+	policy := "allow"
+	if filter.Rule == "denylist" {
+		policy = "deny"
+	}
+	list := make([][]string, len(filter.ChannelList))
+	for i, channelID := range filter.ChannelList {
+		list[i] = []string{"*", channelID}
+	}
+	return &hermesPacketFilter{Policy: policy, List: list}
+}
+
+func withScheme(addr, scheme string) string {
+	if strings.Contains(addr, "://") {
+		return addr
+	}
+	return scheme + "://" + addr
+}
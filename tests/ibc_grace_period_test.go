@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"cosmossdk.io/math"
 	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
@@ -105,7 +106,7 @@ func TestIBCGracePeriodCompliance_EVM(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
@@ -133,6 +134,8 @@ func TestIBCGracePeriodCompliance_EVM(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	walletAmount := math.NewInt(1_000_000_000_000)
 
 	// Create some user accounts on both chains
@@ -168,7 +171,7 @@ func TestIBCGracePeriodCompliance_EVM(t *testing.T) {
 	require.NoError(t, err)
 
 	// Compose an IBC transfer and send from Hub -> rollapp
-	_, err = dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	hubTx, err := dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
 	require.NoError(t, err)
 	// Assert balance was updated on the hub
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount.Sub(transferData.Amount))
@@ -184,6 +187,12 @@ func TestIBCGracePeriodCompliance_EVM(t *testing.T) {
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount.Sub(transferData.Amount))
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, dymensionIBCDenom, transferData.Amount)
 
+	// the acknowledgement of the rollapp is held by the hub until finalization
+	ackCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	heldAck, err := WaitForRollappPacket(ackCtx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, hubTx.Packet, stateStatusPending)
+	require.NoError(t, err)
+
 	channel, err = ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
 	require.NoError(t, err)
 
@@ -193,7 +202,7 @@ func TestIBCGracePeriodCompliance_EVM(t *testing.T) {
 		Amount:  transferAmount,
 	}
 
-	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	rollappTx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
 	require.NoError(t, err)
 
 	// Assert balance was updated on the rollapp because transfer amount was deducted from wallet balance
@@ -210,9 +219,18 @@ func TestIBCGracePeriodCompliance_EVM(t *testing.T) {
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferData.Amount))
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, math.NewInt(0))
 
-	// Wait a 40 blocks
-	err = testutil.WaitForBlocks(ctx, 40, dymension, rollapp1)
+	// the transfer is held by the hub until its proof height is finalized
+	heldRecv := RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, rollappTx.Packet)
+	release, err := NewStateWatcher(dymension, rollapp1.GetChainID()).PredictPacketRelease(ctx, *heldRecv)
 	require.NoError(t, err)
+	fmt.Println("Held packet:", heldRecv, "release:", release)
+
+	// both packets are released in the block that finalizes their proof height
+	releaseHeight := RequirePacketReleasedAtFinalization(t, ctx, dymension, *heldRecv)
+	if release.Submitted() {
+		require.Equal(t, release.FinalizationHubHeight, releaseHeight)
+	}
+	RequirePacketReleasedAtFinalization(t, ctx, dymension, *heldAck)
 
 	// Assert balance was updated on the Hub
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferData.Amount))
@@ -314,7 +332,7 @@ func TestIBCGracePeriodCompliance_Wasm(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
@@ -342,6 +360,8 @@ func TestIBCGracePeriodCompliance_Wasm(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	walletAmount := math.NewInt(1_000_000_000_000)
 
 	// Create some user accounts on both chains
@@ -377,7 +397,7 @@ func TestIBCGracePeriodCompliance_Wasm(t *testing.T) {
 	require.NoError(t, err)
 
 	// Compose an IBC transfer and send from Hub -> rollapp
-	_, err = dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	hubTx, err := dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
 	require.NoError(t, err)
 	// Assert balance was updated on the hub
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount.Sub(transferData.Amount))
@@ -393,6 +413,12 @@ func TestIBCGracePeriodCompliance_Wasm(t *testing.T) {
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount.Sub(transferData.Amount))
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, dymensionIBCDenom, transferData.Amount)
 
+	// the acknowledgement of the rollapp is held by the hub until finalization
+	ackCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+	heldAck, err := WaitForRollappPacket(ackCtx, dymension, rollapp1.GetChainID(), rollappPacketOnAck, hubTx.Packet, stateStatusPending)
+	require.NoError(t, err)
+
 	channel, err = ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
 	require.NoError(t, err)
 
@@ -402,7 +428,7 @@ func TestIBCGracePeriodCompliance_Wasm(t *testing.T) {
 		Amount:  transferAmount,
 	}
 
-	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	rollappTx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
 	require.NoError(t, err)
 
 	// Assert balance was updated on the rollapp because transfer amount was deducted from wallet balance
@@ -419,9 +445,18 @@ func TestIBCGracePeriodCompliance_Wasm(t *testing.T) {
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferData.Amount))
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, math.NewInt(0))
 
-	// Wait a 40 blocks
-	err = testutil.WaitForBlocks(ctx, 40, dymension, rollapp1)
+	// the transfer is held by the hub until its proof height is finalized
+	heldRecv := RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, rollappTx.Packet)
+	release, err := NewStateWatcher(dymension, rollapp1.GetChainID()).PredictPacketRelease(ctx, *heldRecv)
 	require.NoError(t, err)
+	fmt.Println("Held packet:", heldRecv, "release:", release)
+
+	// both packets are released in the block that finalizes their proof height
+	releaseHeight := RequirePacketReleasedAtFinalization(t, ctx, dymension, *heldRecv)
+	if release.Submitted() {
+		require.Equal(t, release.FinalizationHubHeight, releaseHeight)
+	}
+	RequirePacketReleasedAtFinalization(t, ctx, dymension, *heldAck)
 
 	// Assert balance was updated on the Hub
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferData.Amount))
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+)
+
+const (
+	packetEventRecv = "recv_packet"
+	packetEventAck  = "acknowledge_packet"
+)
+
+// CountPacketEvents counts the IBC packet events of the given type emitted by the transactions of the chain
+// between two heights, by packet sequence. recv_packet events count the packets received on the channel, other
+// types the packets sent on it. A packet relayed more than once would show more than one event, since a
+// redundant relay is rejected without one.
+func CountPacketEvents(ctx context.Context, chain *cosmos.CosmosChain, startHeight, endHeight uint64, eventType, channelID string) (map[uint64]int, error) {
+	channelAttr := "packet_src_channel"
+	if eventType == packetEventRecv {
+		channelAttr = "packet_dst_channel"
+	}
+
+	counts := make(map[uint64]int)
+	for height := startHeight; height <= endHeight; height++ {
+		txs, err := chain.FindTxs(ctx, height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s transactions at height %d: %w", chain.Config().ChainID, height, err)
+		}
+		for _, tx := range txs {
+			for _, event := range tx.Events {
+				if event.Type != eventType {
+					continue
+				}
+				attrs := make(map[string]string, len(event.Attributes))
+				for _, attr := range event.Attributes {
+					attrs[attr.Key] = attr.Value
+				}
+				if attrs[channelAttr] != channelID {
+					continue
+				}
+				sequence, err := strconv.ParseUint(attrs["packet_sequence"], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid packet sequence %q at height %d: %w", attrs["packet_sequence"], height, err)
+				}
+				counts[sequence]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+// QueryPacketCommitments returns the sequences of the packets sent on the channel whose commitment is still
+// stored, the packets not acknowledged or timed out yet.
+func QueryPacketCommitments(ctx context.Context, chain *cosmos.CosmosChain, portID, channelID string) ([]uint64, error) {
+	stdout, _, err := chain.GetNode().ExecQuery(ctx, "ibc", "channel", "packet-commitments", portID, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query packet commitments of %s/%s: %w", portID, channelID, err)
+	}
+
+	var res struct {
+		Commitments []struct {
+			Sequence string `json:"sequence"`
+		} `json:"commitments"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal packet commitments of %s/%s: %w", portID, channelID, err)
+	}
+
+	sequences := make([]uint64, len(res.Commitments))
+	for i, commitment := range res.Commitments {
+		sequences[i], err = strconv.ParseUint(commitment.Sequence, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid packet commitment sequence %q: %w", commitment.Sequence, err)
+		}
+	}
+	return sequences, nil
+}
+
+// QueryUnrelayedPackets returns the sequences of the packets sent by src on the channel that dst has not received
+// yet. The channel is the one of src.
+func QueryUnrelayedPackets(ctx context.Context, src, dst *cosmos.CosmosChain, channel ibc.ChannelOutput) ([]uint64, error) {
+	commitments, err := QueryPacketCommitments(ctx, src, channel.PortID, channel.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if len(commitments) == 0 {
+		return nil, nil
+	}
+	return queryUnreceived(ctx, dst, "unreceived-packets", channel.Counterparty.PortID, channel.Counterparty.ChannelID, commitments)
+}
+
+// QueryUnrelayedAcks returns the sequences of the packets sent by src on the channel that dst has acknowledged but
+// whose acknowledgement src has not received yet. The channel is the one of src.
+func QueryUnrelayedAcks(ctx context.Context, src, dst *cosmos.CosmosChain, channel ibc.ChannelOutput) ([]uint64, error) {
+	acks, err := queryPacketAcks(ctx, dst, channel.Counterparty.PortID, channel.Counterparty.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if len(acks) == 0 {
+		return nil, nil
+	}
+	return queryUnreceived(ctx, src, "unreceived-acks", channel.PortID, channel.ChannelID, acks)
+}
+
+// queryPacketAcks returns the sequences of the packets received on the channel whose acknowledgement is stored.
+func queryPacketAcks(ctx context.Context, chain *cosmos.CosmosChain, portID, channelID string) ([]uint64, error) {
+	stdout, _, err := chain.GetNode().ExecQuery(ctx, "ibc", "channel", "packet-acks", portID, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query packet acks of %s/%s: %w", portID, channelID, err)
+	}
+
+	var res struct {
+		Acknowledgements []struct {
+			Sequence string `json:"sequence"`
+		} `json:"acknowledgements"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal packet acks of %s/%s: %w", portID, channelID, err)
+	}
+
+	sequences := make([]uint64, len(res.Acknowledgements))
+	for i, ack := range res.Acknowledgements {
+		sequences[i], err = strconv.ParseUint(ack.Sequence, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid packet ack sequence %q: %w", ack.Sequence, err)
+		}
+	}
+	return sequences, nil
+}
+
+// queryUnreceived runs the unreceived-packets or unreceived-acks query of the channel for the sequences.
+func queryUnreceived(ctx context.Context, chain *cosmos.CosmosChain, query, portID, channelID string, sequences []uint64) ([]uint64, error) {
+	list := make([]string, len(sequences))
+	for i, sequence := range sequences {
+		list[i] = strconv.FormatUint(sequence, 10)
+	}
+
+	stdout, _, err := chain.GetNode().ExecQuery(ctx, "ibc", "channel", query, portID, channelID, "--sequences", strings.Join(list, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s of %s/%s: %w", query, portID, channelID, err)
+	}
+
+	var res struct {
+		Sequences []string `json:"sequences"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s of %s/%s: %w", query, portID, channelID, err)
+	}
+
+	unreceived := make([]uint64, len(res.Sequences))
+	for i, sequence := range res.Sequences {
+		unreceived[i], err = strconv.ParseUint(sequence, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s sequence %q: %w", query, sequence, err)
+		}
+	}
+	return unreceived, nil
+}
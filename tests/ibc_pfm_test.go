@@ -89,13 +89,11 @@ func TestIBCTransferMultiHop_EVM(t *testing.T) {
 
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
-	r2 := test.NewBuiltinRelayerFactory(
-		ibc.CosmosRly,
-		zaptest.NewLogger(t),
+	r2 := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage(IBCRelayerImage, IBCRelayerVersion, "100:1000"),
 	).Build(t, client, "relayer2", network)
 
@@ -130,6 +128,8 @@ func TestIBCTransferMultiHop_EVM(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	t.Cleanup(func() {
 		_ = ic.Close()
 	})
@@ -248,6 +248,7 @@ func TestIBCTransferMultiHop_EVM(t *testing.T) {
 		require.NoError(t, err)
 		err = transferTx.Validate()
 		require.NoError(t, err)
+		TracePacketOnFailure(t, ctx, rollapp1.CosmosChain, transferTx.TxHash, dymension.CosmosChain, gaia)
 
 		err = testutil.WaitForBlocks(ctx, 40, rollapp1, gaia)
 		require.NoError(t, err)
@@ -325,13 +326,11 @@ func TestIBCTransferMultiHop_Wasm(t *testing.T) {
 
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
-	r2 := test.NewBuiltinRelayerFactory(
-		ibc.CosmosRly,
-		zaptest.NewLogger(t),
+	r2 := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage(IBCRelayerImage, IBCRelayerVersion, "100:1000"),
 	).Build(t, client, "relayer2", network)
 
@@ -366,6 +365,8 @@ func TestIBCTransferMultiHop_Wasm(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	t.Cleanup(func() {
 		_ = ic.Close()
 	})
@@ -484,6 +485,7 @@ func TestIBCTransferMultiHop_Wasm(t *testing.T) {
 		require.NoError(t, err)
 		err = transferTx.Validate()
 		require.NoError(t, err)
+		TracePacketOnFailure(t, ctx, rollapp1.CosmosChain, transferTx.TxHash, dymension.CosmosChain, gaia)
 
 		err = testutil.WaitForBlocks(ctx, 40, rollapp1, gaia)
 		require.NoError(t, err)
@@ -112,14 +112,11 @@ func TestIBCPFMWithGracePeriod_EVM(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(
-		ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
-	r2 := test.NewBuiltinRelayerFactory(
-		ibc.CosmosRly,
-		zaptest.NewLogger(t),
+	r2 := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage(IBCRelayerImage, IBCRelayerVersion, "100:1000"),
 	).Build(t, client, "relayer2", network)
 
@@ -154,6 +151,8 @@ func TestIBCPFMWithGracePeriod_EVM(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	t.Cleanup(func() {
 		_ = ic.Close()
 	})
@@ -290,7 +289,12 @@ func TestIBCPFMWithGracePeriod_EVM(t *testing.T) {
 		require.True(t, dymBalance.Equal(zeroBal))
 		require.True(t, gaiaBalance.Equal(zeroBal))
 
-		err = testutil.WaitForBlocks(ctx, 100, rollapp1)
+		// the hub holds the packet, and only forwards it once its proof height is finalized
+		held := RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, transferTx.Packet)
+		releaseHeight := RequirePacketReleasedAtFinalization(t, ctx, dymension, *held)
+		fmt.Println("Forwarded packet released at hub height:", releaseHeight)
+
+		err = testutil.WaitForBlocks(ctx, 20, dymension, gaia)
 		require.NoError(t, err)
 
 		gaiaBalance, err = gaia.GetBalance(ctx, gaiaUserAddr, secondHopIBCDenom)
@@ -390,14 +394,11 @@ func TestIBCPFMWithGracePeriod_Wasm(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(
-		ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
-	r2 := test.NewBuiltinRelayerFactory(
-		ibc.CosmosRly,
-		zaptest.NewLogger(t),
+	r2 := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage(IBCRelayerImage, IBCRelayerVersion, "100:1000"),
 	).Build(t, client, "relayer2", network)
 
@@ -432,6 +433,8 @@ func TestIBCPFMWithGracePeriod_Wasm(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	t.Cleanup(func() {
 		_ = ic.Close()
 	})
@@ -568,7 +571,12 @@ func TestIBCPFMWithGracePeriod_Wasm(t *testing.T) {
 		require.True(t, dymBalance.Equal(zeroBal))
 		require.True(t, gaiaBalance.Equal(zeroBal))
 
-		err = testutil.WaitForBlocks(ctx, 100, rollapp1)
+		// the hub holds the packet, and only forwards it once its proof height is finalized
+		held := RequirePacketHeld(t, ctx, dymension, rollapp1.GetChainID(), rollappPacketOnRecv, transferTx.Packet)
+		releaseHeight := RequirePacketReleasedAtFinalization(t, ctx, dymension, *held)
+		fmt.Println("Forwarded packet released at hub height:", releaseHeight)
+
+		err = testutil.WaitForBlocks(ctx, 20, dymension, gaia)
 		require.NoError(t, err)
 
 		gaiaBalance, err = gaia.GetBalance(ctx, gaiaUserAddr, secondHopIBCDenom)
@@ -83,7 +83,7 @@ func TestIBCTransferSuccess_EVM(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
@@ -111,6 +111,8 @@ func TestIBCTransferSuccess_EVM(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	walletAmount := math.NewInt(1_000_000_000_000)
 
 	// Create some user accounts on both chains
@@ -159,8 +161,9 @@ func TestIBCTransferSuccess_EVM(t *testing.T) {
 	}
 
 	// Compose an IBC transfer and send from Hub -> rollapp
-	_, err = dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	hubTx, err := dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
 	require.NoError(t, err)
+	TracePacketOnFailure(t, ctx, dymension.CosmosChain, hubTx.TxHash, rollapp1.CosmosChain)
 	// Assert balance was updated on the hub
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount.Sub(transferData.Amount))
 
@@ -181,8 +184,9 @@ func TestIBCTransferSuccess_EVM(t *testing.T) {
 	}
 
 	// Compose an IBC transfer and send from rollapp -> Hub
-	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	rollappTx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
 	require.NoError(t, err)
+	TracePacketOnFailure(t, ctx, rollapp1.CosmosChain, rollappTx.TxHash, dymension.CosmosChain)
 
 	// Assert balance was updated on the hub
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferData.Amount))
@@ -197,6 +201,12 @@ func TestIBCTransferSuccess_EVM(t *testing.T) {
 	// Assert funds were returned to the sender after the timeout has occured
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferData.Amount))
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// The transfer from the hub went through its whole lifecycle, in order
+	hubTrace, err := TracePacket(ctx, dymension.CosmosChain, hubTx.TxHash, rollapp1.CosmosChain)
+	require.NoError(t, err)
+	require.Equal(t, []string{packetEventSend, packetEventRecv, packetEventWriteAck, packetEventAck}, hubTrace.Types(),
+		"packets of tx %s on %s:\n%s", hubTx.TxHash, dymension.Config().ChainID, hubTrace)
 }
 
 // TestIBCTransferSuccess ensure that the transfer between Hub and Rollapp is accurate.
@@ -262,7 +272,7 @@ func TestIBCTransferSuccess_Wasm(t *testing.T) {
 	// Relayer Factory
 	client, network := test.DockerSetup(t)
 
-	r := test.NewBuiltinRelayerFactory(ibc.CosmosRly, zaptest.NewLogger(t),
+	r := NewRelayerFactory(zaptest.NewLogger(t),
 		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
 	).Build(t, client, "relayer", network)
 
@@ -290,6 +300,8 @@ func TestIBCTransferSuccess_Wasm(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	walletAmount := math.NewInt(1_000_000_000_000)
 
 	// Create some user accounts on both chains
@@ -338,8 +350,9 @@ func TestIBCTransferSuccess_Wasm(t *testing.T) {
 	}
 
 	// Compose an IBC transfer and send from Hub -> rollapp
-	_, err = dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	hubTx, err := dymension.SendIBCTransfer(ctx, channel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
 	require.NoError(t, err)
+	TracePacketOnFailure(t, ctx, dymension.CosmosChain, hubTx.TxHash, rollapp1.CosmosChain)
 	// Assert balance was updated on the hub
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, dymension.Config().Denom, walletAmount.Sub(transferData.Amount))
 
@@ -360,8 +373,9 @@ func TestIBCTransferSuccess_Wasm(t *testing.T) {
 	}
 
 	// Compose an IBC transfer and send from rollapp -> Hub
-	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	rollappTx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
 	require.NoError(t, err)
+	TracePacketOnFailure(t, ctx, rollapp1.CosmosChain, rollappTx.TxHash, dymension.CosmosChain)
 
 	// Assert balance was updated on the hub
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferData.Amount))
@@ -376,4 +390,10 @@ func TestIBCTransferSuccess_Wasm(t *testing.T) {
 	// Assert funds were returned to the sender after the timeout has occured
 	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, walletAmount.Sub(transferData.Amount))
 	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// The transfer from the hub went through its whole lifecycle, in order
+	hubTrace, err := TracePacket(ctx, dymension.CosmosChain, hubTx.TxHash, rollapp1.CosmosChain)
+	require.NoError(t, err)
+	require.Equal(t, []string{packetEventSend, packetEventRecv, packetEventWriteAck, packetEventAck}, hubTrace.Types(),
+		"packets of tx %s on %s:\n%s", hubTx.TxHash, dymension.Config().ChainID, hubTrace)
 }
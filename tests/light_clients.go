@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	clienttypes "github.com/cosmos/ibc-go/v6/modules/core/02-client/types"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+)
+
+const (
+	clientStatusActive  = "Active"
+	clientStatusExpired = "Expired"
+	clientStatusFrozen  = "Frozen"
+
+	clientStatusPollInterval = 2 * time.Second
+
+	// proposalPassHeightDelta is how many blocks a proposal is given to pass, far more than the voting periods of
+	// the test genesis
+	proposalPassHeightDelta = 100
+)
+
+// QueryClientStatus returns the status of the light client on the chain: Active, Expired or Frozen.
+func QueryClientStatus(ctx context.Context, chain *cosmos.CosmosChain, clientID string) (string, error) {
+	stdout, _, err := chain.GetNode().ExecQuery(ctx, "ibc", "client", "status", clientID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query status of client %s on %s: %w", clientID, chain.Config().ChainID, err)
+	}
+
+	var res struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return "", fmt.Errorf("failed to unmarshal status of client %s: %w", clientID, err)
+	}
+	return res.Status, nil
+}
+
+// QueryClientLatestHeight returns the latest height of the counterparty the light client on the chain holds a
+// consensus state for.
+func QueryClientLatestHeight(ctx context.Context, chain *cosmos.CosmosChain, clientID string) (clienttypes.Height, error) {
+	stdout, _, err := chain.GetNode().ExecQuery(ctx, "ibc", "client", "state", clientID)
+	if err != nil {
+		return clienttypes.Height{}, fmt.Errorf("failed to query state of client %s on %s: %w", clientID, chain.Config().ChainID, err)
+	}
+
+	var res struct {
+		ClientState struct {
+			LatestHeight struct {
+				RevisionNumber uint64 `json:"revision_number,string"`
+				RevisionHeight uint64 `json:"revision_height,string"`
+			} `json:"latest_height"`
+		} `json:"client_state"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return clienttypes.Height{}, fmt.Errorf("failed to unmarshal state of client %s: %w", clientID, err)
+	}
+	latest := res.ClientState.LatestHeight
+	return clienttypes.NewHeight(latest.RevisionNumber, latest.RevisionHeight), nil
+}
+
+// WaitForClientStatus waits until the light client on the chain has the status.
+func WaitForClientStatus(ctx context.Context, chain *cosmos.CosmosChain, clientID, status string) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(clientStatusPollInterval)
+	defer ticker.Stop()
+
+	var last string
+	for {
+		current, err := QueryClientStatus(ctx, chain, clientID)
+		if err == nil {
+			if current == status {
+				return nil
+			}
+			last = current
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("client %s on %s still %s, want %s: %w", clientID, chain.Config().ChainID, last, status, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// ChannelClientID returns the light client the channel of the chain is built on.
+func ChannelClientID(ctx context.Context, r ibc.Relayer, rep ibc.RelayerExecReporter, chainID, channelID string) (string, error) {
+	channel, err := findChannel(ctx, r, rep, chainID, channelID)
+	if err != nil {
+		return "", err
+	}
+	if len(channel.ConnectionHops) == 0 {
+		return "", fmt.Errorf("channel %s on %s has no connection", channelID, chainID)
+	}
+
+	connections, err := r.GetConnections(ctx, rep, chainID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get connections of %s: %w", chainID, err)
+	}
+	for _, connection := range connections {
+		if connection.ID == channel.ConnectionHops[0] {
+			return connection.ClientID, nil
+		}
+	}
+	return "", fmt.Errorf("connection %s not found on %s", channel.ConnectionHops[0], chainID)
+}
+
+// LatestClientID returns the light client of the counterparty chain last created on the host chain.
+func LatestClientID(ctx context.Context, r ibc.Relayer, rep ibc.RelayerExecReporter, hostChainID, counterpartyChainID string) (string, error) {
+	clients, err := r.GetClients(ctx, rep, hostChainID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get clients of %s: %w", hostChainID, err)
+	}
+
+	var latest string
+	var latestSeq uint64
+	for _, client := range clients {
+		if client.ClientState.ChainID != counterpartyChainID {
+			continue
+		}
+		// client IDs end with a sequence, 07-tendermint-3
+		seq, err := strconv.ParseUint(client.ClientID[strings.LastIndex(client.ClientID, "-")+1:], 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid client ID %s: %w", client.ClientID, err)
+		}
+		if latest == "" || seq > latestSeq {
+			latest, latestSeq = client.ClientID, seq
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no client of %s on %s", counterpartyChainID, hostChainID)
+	}
+	return latest, nil
+}
+
+// SubstituteClient recovers an expired or frozen light client through a client update proposal, which gives the
+// subject client the state of the substitute client, and votes it through.
+func SubstituteClient(ctx context.Context, chain *cosmos.CosmosChain, keyName, subjectClientID, substituteClientID string) error {
+	height, err := chain.Height(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get %s height: %w", chain.Config().ChainID, err)
+	}
+
+	txHash, err := chain.GetNode().ExecTx(ctx, keyName,
+		"gov", "submit-legacy-proposal", "update-client", subjectClientID, substituteClientID,
+		"--title", "Recover client "+subjectClientID,
+		"--description", fmt.Sprintf("Substitute client %s with %s", subjectClientID, substituteClientID),
+		"--deposit", "500000000000"+chain.Config().Denom, // greater than min deposit
+		"--gas=auto",
+	)
+	if err != nil {
+		return fmt.Errorf("failed to submit client update proposal of %s: %w", subjectClientID, err)
+	}
+	txResp, err := chain.GetTransaction(txHash)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction %s: %w", txHash, err)
+	}
+	proposalID, found := cosmos.AttributeValue(txResp.Events, "submit_proposal", "proposal_id")
+	if !found {
+		return fmt.Errorf("no proposal in transaction %s", txHash)
+	}
+
+	if err := chain.VoteOnProposalAllValidators(ctx, proposalID, cosmos.ProposalVoteYes); err != nil {
+		return fmt.Errorf("failed to vote on client update proposal %s: %w", proposalID, err)
+	}
+	if _, err := cosmos.PollForProposalStatus(ctx, chain, height, height+proposalPassHeightDelta, proposalID, cosmos.ProposalStatusPassed); err != nil {
+		return fmt.Errorf("client update proposal %s did not pass: %w", proposalID, err)
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	rly "github.com/decentrio/rollup-e2e-testing/relayer/rly"
+)
+
+// ErrSequenceSelectionUnsupported is returned when the relayer cannot relay selected packet sequences only.
+var ErrSequenceSelectionUnsupported = errors.New("relayer cannot relay selected packet sequences")
+
+// ManualRelayer drives the IBC steps of a path one at a time, for tests that keep the relayer stopped and relay
+// explicitly instead of waiting for blocks. The channel passed is the channel the packets were sent on, on the
+// chain they were sent from.
+type ManualRelayer interface {
+	// RelayPackets relays the packets sent on the channel that the counterparty has not received, or only the
+	// given sequences.
+	RelayPackets(ctx context.Context, rep ibc.RelayerExecReporter, pathName, srcChainID, srcChannelID string, sequences ...uint64) error
+	// RelayAcks relays back the acknowledgements of the packets sent on the channel, or of the given sequences.
+	RelayAcks(ctx context.Context, rep ibc.RelayerExecReporter, pathName, srcChainID, srcChannelID string, sequences ...uint64) error
+	// Flush relays every pending packet and acknowledgement of the channel, in both directions.
+	Flush(ctx context.Context, rep ibc.RelayerExecReporter, pathName, channelID string) error
+}
+
+// NewManualRelayer returns the manual relaying API of the relayer.
+func NewManualRelayer(r ibc.Relayer) (ManualRelayer, error) {
+	switch r := r.(type) {
+	case *HermesRelayer:
+		return r, nil
+	case *rly.CosmosRelayer:
+		return rlyManualRelayer{r}, nil
+	default:
+		return nil, fmt.Errorf("relayer %T cannot relay manually", r)
+	}
+}
+
+// findChannel returns the channel of the chain as the relayer sees it.
+func findChannel(ctx context.Context, r ibc.Relayer, rep ibc.RelayerExecReporter, chainID, channelID string) (ibc.ChannelOutput, error) {
+	channels, err := r.GetChannels(ctx, rep, chainID)
+	if err != nil {
+		return ibc.ChannelOutput{}, fmt.Errorf("failed to get channels of %s: %w", chainID, err)
+	}
+	for _, channel := range channels {
+		if channel.ChannelID == channelID {
+			return channel, nil
+		}
+	}
+	return ibc.ChannelOutput{}, fmt.Errorf("channel %s not found on %s", channelID, chainID)
+}
+
+// rlyManualRelayer relays manually with rly. rly flushes a channel as a whole, relaying every pending packet and
+// acknowledgement in both directions, so a call without sequences relays all of them, and a call with sequences
+// only goes through when nothing else is pending on the channel.
+type rlyManualRelayer struct {
+	*rly.CosmosRelayer
+}
+
+func (r rlyManualRelayer) RelayPackets(ctx context.Context, rep ibc.RelayerExecReporter, pathName, srcChainID, srcChannelID string, sequences ...uint64) error {
+	return r.relay(ctx, rep, "relay-packets", pathName, srcChainID, srcChannelID, sequences)
+}
+
+func (r rlyManualRelayer) RelayAcks(ctx context.Context, rep ibc.RelayerExecReporter, pathName, srcChainID, srcChannelID string, sequences ...uint64) error {
+	return r.relay(ctx, rep, "relay-acks", pathName, srcChainID, srcChannelID, sequences)
+}
+
+func (r rlyManualRelayer) relay(ctx context.Context, rep ibc.RelayerExecReporter, subcommand, pathName, srcChainID, srcChannelID string, sequences []uint64) error {
+	// rly takes the channel of the source chain of the path
+	pathSrc, err := r.pathSrcChainID(ctx, rep, pathName)
+	if err != nil {
+		return err
+	}
+	channelID := srcChannelID
+	if pathSrc != srcChainID {
+		channel, err := findChannel(ctx, r, rep, srcChainID, srcChannelID)
+		if err != nil {
+			return err
+		}
+		channelID = channel.Counterparty.ChannelID
+	}
+
+	if len(sequences) > 0 {
+		others, err := r.pendingBesides(ctx, rep, subcommand, pathName, channelID, pathSrc == srcChainID, sequences)
+		if err != nil {
+			return err
+		}
+		if len(others) > 0 {
+			return fmt.Errorf("rly %s cannot relay sequences %v while %s are pending on path %s: %w",
+				subcommand, sequences, strings.Join(others, ", "), pathName, ErrSequenceSelectionUnsupported)
+		}
+	}
+
+	cmd := []string{"rly", "tx", subcommand, pathName, channelID, "--home", r.HomeDir()}
+	if res := r.Exec(ctx, rep, cmd, nil); res.Err != nil {
+		return fmt.Errorf("rly %s failed on path %s: %w", subcommand, pathName, res.Err)
+	}
+	return nil
+}
+
+// pendingBesides returns what rly would relay on the channel besides the selected sequences, looking at the packets
+// and acknowledgements pending in both directions. The selected sequences are packets or acknowledgements, depending
+// on the subcommand, of the packets sent from the source chain of the path or from the other chain.
+func (r rlyManualRelayer) pendingBesides(ctx context.Context, rep ibc.RelayerExecReporter, subcommand, pathName, channelID string, fromPathSrc bool, sequences []uint64) ([]string, error) {
+	selectedQuery := "unrelayed-packets"
+	if subcommand == "relay-acks" {
+		selectedQuery = "unrelayed-acknowledgements"
+	}
+
+	var others []string
+	for _, query := range []string{"unrelayed-packets", "unrelayed-acknowledgements"} {
+		cmd := []string{"rly", "q", query, pathName, channelID, "--home", r.HomeDir()}
+		res := r.Exec(ctx, rep, cmd, nil)
+		if res.Err != nil {
+			return nil, fmt.Errorf("failed to query %s on path %s: %w", query, pathName, res.Err)
+		}
+
+		var out struct {
+			Src []uint64 `json:"src"`
+			Dst []uint64 `json:"dst"`
+		}
+		if err := json.Unmarshal(res.Stdout, &out); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s on path %s: %w", query, pathName, err)
+		}
+
+		// the packets are pending on the chain that sent them, their acknowledgements on the chain that received them
+		selected := query == selectedQuery
+		onSrc := fromPathSrc == (query == "unrelayed-packets")
+		sides := []struct {
+			name      string
+			pending   []uint64
+			selection bool
+		}{
+			{"src", out.Src, selected && onSrc},
+			{"dst", out.Dst, selected && !onSrc},
+		}
+		for _, side := range sides {
+			pending := side.pending
+			if side.selection {
+				pending = withoutSequences(pending, sequences)
+			}
+			if len(pending) > 0 {
+				others = append(others, fmt.Sprintf("%s %v on the path %s", query, pending, side.name))
+			}
+		}
+	}
+	return others, nil
+}
+
+// withoutSequences returns the sequences that are not selected.
+func withoutSequences(sequences, selected []uint64) []uint64 {
+	var others []uint64
+	for _, sequence := range sequences {
+		if !slices.Contains(selected, sequence) {
+			others = append(others, sequence)
+		}
+	}
+	return others
+}
+
+// pathSrcChainID returns the chain ID of the source chain of the path.
+func (r rlyManualRelayer) pathSrcChainID(ctx context.Context, rep ibc.RelayerExecReporter, pathName string) (string, error) {
+	cmd := []string{"rly", "paths", "show", pathName, "--json", "--home", r.HomeDir()}
+	res := r.Exec(ctx, rep, cmd, nil)
+	if res.Err != nil {
+		return "", fmt.Errorf("failed to show path %s: %w", pathName, res.Err)
+	}
+
+	var out struct {
+		Path struct {
+			Src struct {
+				ChainID string `json:"chain-id"`
+			} `json:"src"`
+		} `json:"path"`
+	}
+	if err := json.Unmarshal(res.Stdout, &out); err != nil {
+		return "", fmt.Errorf("failed to unmarshal path %s: %w", pathName, err)
+	}
+	return out.Path.Src.ChainID, nil
+}
+
+// RelayPackets relays the packets sent on the channel of the source chain to the other chain of the path.
+func (r *HermesRelayer) RelayPackets(ctx context.Context, rep ibc.RelayerExecReporter, pathName, srcChainID, srcChannelID string, sequences ...uint64) error {
+	dstChainID, err := r.counterpartyChainID(pathName, srcChainID)
+	if err != nil {
+		return err
+	}
+	channel, err := findChannel(ctx, r, rep, srcChainID, srcChannelID)
+	if err != nil {
+		return err
+	}
+
+	cmd := hermesCmd(r.HomeDir(), "tx", "packet-recv",
+		"--dst-chain", dstChainID,
+		"--src-chain", srcChainID,
+		"--src-port", channel.PortID,
+		"--src-channel", channel.ChannelID,
+	)
+	if err := r.execJSON(ctx, rep, withPacketSequences(cmd, sequences), nil); err != nil {
+		return fmt.Errorf("failed to relay packets of %s/%s on %s: %w", channel.PortID, channel.ChannelID, srcChainID, err)
+	}
+	return nil
+}
+
+// RelayAcks relays the acknowledgements written by the other chain of the path back to the source chain.
+func (r *HermesRelayer) RelayAcks(ctx context.Context, rep ibc.RelayerExecReporter, pathName, srcChainID, srcChannelID string, sequences ...uint64) error {
+	dstChainID, err := r.counterpartyChainID(pathName, srcChainID)
+	if err != nil {
+		return err
+	}
+	channel, err := findChannel(ctx, r, rep, srcChainID, srcChannelID)
+	if err != nil {
+		return err
+	}
+
+	// the acknowledgements travel from the receiving chain, which is their source for hermes
+	cmd := hermesCmd(r.HomeDir(), "tx", "packet-ack",
+		"--dst-chain", srcChainID,
+		"--src-chain", dstChainID,
+		"--src-port", channel.Counterparty.PortID,
+		"--src-channel", channel.Counterparty.ChannelID,
+	)
+	if err := r.execJSON(ctx, rep, withPacketSequences(cmd, sequences), nil); err != nil {
+		return fmt.Errorf("failed to relay acks of %s/%s on %s: %w", channel.PortID, channel.ChannelID, srcChainID, err)
+	}
+	return nil
+}
+
+// counterpartyChainID returns the chain at the other end of the path.
+func (r *HermesRelayer) counterpartyChainID(pathName, chainID string) (string, error) {
+	p, err := r.path(pathName)
+	if err != nil {
+		return "", err
+	}
+	switch chainID {
+	case p.chainA.chainID:
+		return p.chainB.chainID, nil
+	case p.chainB.chainID:
+		return p.chainA.chainID, nil
+	default:
+		return "", fmt.Errorf("chain %s is not on path %s", chainID, pathName)
+	}
+}
+
+func withPacketSequences(cmd []string, sequences []uint64) []string {
+	if len(sequences) == 0 {
+		return cmd
+	}
+	list := make([]string, len(sequences))
+	for i, sequence := range sequences {
+		list[i] = strconv.FormatUint(sequence, 10)
+	}
+	return append(cmd, "--packet-sequences", strings.Join(list, ","))
+}
@@ -0,0 +1,544 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// This test case never starts the relayer and drives every IBC step of a transfer each way through the manual
+// relaying API: the packets stay unrelayed until relayed explicitly, a packet selected by its sequence is relayed
+// while the next one stays unrelayed where the relayer can select sequences, the acknowledgements of the transfers
+// to the rollapp are relayed on their own, and the acknowledgement of a transfer from the rollapp is only written by the
+// hub once the height of the packet is finalized
+func TestManualRelay_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	// The relayer is never started, every IBC step below is relayed explicitly
+	manual, err := NewManualRelayer(r)
+	require.NoError(t, err)
+
+	hubChannel, err := findChannel(ctx, r, eRep, dymension.Config().ChainID, channel.Counterparty.ChannelID)
+	require.NoError(t, err)
+	rollappChannel, err := findChannel(ctx, r, eRep, rollapp1.Config().ChainID, channel.ChannelID)
+	require.NoError(t, err)
+
+	transferAmount := math.NewInt(1_000_000)
+
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// Send twice from the hub, the packets wait until they are relayed
+	transferData := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	tx, err := dymension.SendIBCTransfer(ctx, hubChannel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	hubSequence := tx.Packet.Sequence
+	tx, err = dymension.SendIBCTransfer(ctx, hubChannel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	nextHubSequence := tx.Packet.Sequence
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	unrelayed, err := QueryUnrelayedPackets(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{hubSequence, nextHubSequence}, unrelayed)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, math.ZeroInt())
+
+	// Relay the first packet only, the rollapp acknowledges it right away and the second one stays unrelayed
+	err = manual.RelayPackets(ctx, eRep, ibcPath, dymension.Config().ChainID, hubChannel.ChannelID, hubSequence)
+	if errors.Is(err, ErrSequenceSelectionUnsupported) {
+		// rly relays the whole channel, it refuses to relay the first packet without the second one
+		unrelayed, err = QueryUnrelayedPackets(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{hubSequence, nextHubSequence}, unrelayed)
+		testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, math.ZeroInt())
+	} else {
+		require.NoError(t, err)
+
+		unrelayed, err = QueryUnrelayedPackets(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{nextHubSequence}, unrelayed)
+		testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+
+		unrelayedAcks, err := QueryUnrelayedAcks(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{hubSequence}, unrelayedAcks)
+	}
+
+	// Relay what is left once the selection covers every pending packet
+	err = manual.RelayPackets(ctx, eRep, ibcPath, dymension.Config().ChainID, hubChannel.ChannelID, hubSequence, nextHubSequence)
+	require.NoError(t, err)
+
+	unrelayed, err = QueryUnrelayedPackets(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayed)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount.MulRaw(2))
+
+	unrelayedAcks, err := QueryUnrelayedAcks(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{hubSequence, nextHubSequence}, unrelayedAcks)
+
+	// Relay the acknowledgements, which clears the commitments on the hub
+	err = manual.RelayAcks(ctx, eRep, ibcPath, dymension.Config().ChainID, hubChannel.ChannelID)
+	require.NoError(t, err)
+
+	unrelayedAcks, err = QueryUnrelayedAcks(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayedAcks)
+	commitments, err := QueryPacketCommitments(ctx, dymension.CosmosChain, hubChannel.PortID, hubChannel.ChannelID)
+	require.NoError(t, err)
+	require.Empty(t, commitments)
+
+	// Send from the rollapp, the hub holds back the acknowledgement until the height of the packet is finalized
+	transferData = ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	tx, err = rollapp1.SendIBCTransfer(ctx, rollappChannel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	rollappSequence := tx.Packet.Sequence
+
+	rollappHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	unrelayed, err = QueryUnrelayedPackets(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{rollappSequence}, unrelayed)
+
+	err = manual.RelayPackets(ctx, eRep, ibcPath, rollapp1.Config().ChainID, rollappChannel.ChannelID)
+	require.NoError(t, err)
+
+	unrelayed, err = QueryUnrelayedPackets(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayed)
+	unrelayedAcks, err = QueryUnrelayedAcks(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayedAcks)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	// The finalization writes the acknowledgement and credits the transfer on the hub
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	unrelayedAcks, err = QueryUnrelayedAcks(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{rollappSequence}, unrelayedAcks)
+
+	err = manual.RelayAcks(ctx, eRep, ibcPath, rollapp1.Config().ChainID, rollappChannel.ChannelID)
+	require.NoError(t, err)
+
+	unrelayedAcks, err = QueryUnrelayedAcks(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayedAcks)
+	commitments, err = QueryPacketCommitments(ctx, rollapp1.CosmosChain, rollappChannel.PortID, rollappChannel.ChannelID)
+	require.NoError(t, err)
+	require.Empty(t, commitments)
+}
+
+func TestManualRelay_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	// The relayer is never started, every IBC step below is relayed explicitly
+	manual, err := NewManualRelayer(r)
+	require.NoError(t, err)
+
+	hubChannel, err := findChannel(ctx, r, eRep, dymension.Config().ChainID, channel.Counterparty.ChannelID)
+	require.NoError(t, err)
+	rollappChannel, err := findChannel(ctx, r, eRep, rollapp1.Config().ChainID, channel.ChannelID)
+	require.NoError(t, err)
+
+	transferAmount := math.NewInt(1_000_000)
+
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+
+	// Send twice from the hub, the packets wait until they are relayed
+	transferData := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	tx, err := dymension.SendIBCTransfer(ctx, hubChannel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	hubSequence := tx.Packet.Sequence
+	tx, err = dymension.SendIBCTransfer(ctx, hubChannel.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	nextHubSequence := tx.Packet.Sequence
+
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	unrelayed, err := QueryUnrelayedPackets(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{hubSequence, nextHubSequence}, unrelayed)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, math.ZeroInt())
+
+	// Relay the first packet only, the rollapp acknowledges it right away and the second one stays unrelayed
+	err = manual.RelayPackets(ctx, eRep, ibcPath, dymension.Config().ChainID, hubChannel.ChannelID, hubSequence)
+	if errors.Is(err, ErrSequenceSelectionUnsupported) {
+		// rly relays the whole channel, it refuses to relay the first packet without the second one
+		unrelayed, err = QueryUnrelayedPackets(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{hubSequence, nextHubSequence}, unrelayed)
+		testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, math.ZeroInt())
+	} else {
+		require.NoError(t, err)
+
+		unrelayed, err = QueryUnrelayedPackets(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{nextHubSequence}, unrelayed)
+		testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+
+		unrelayedAcks, err := QueryUnrelayedAcks(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+		require.NoError(t, err)
+		require.Equal(t, []uint64{hubSequence}, unrelayedAcks)
+	}
+
+	// Relay what is left once the selection covers every pending packet
+	err = manual.RelayPackets(ctx, eRep, ibcPath, dymension.Config().ChainID, hubChannel.ChannelID, hubSequence, nextHubSequence)
+	require.NoError(t, err)
+
+	unrelayed, err = QueryUnrelayedPackets(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayed)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount.MulRaw(2))
+
+	unrelayedAcks, err := QueryUnrelayedAcks(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{hubSequence, nextHubSequence}, unrelayedAcks)
+
+	// Relay the acknowledgements, which clears the commitments on the hub
+	err = manual.RelayAcks(ctx, eRep, ibcPath, dymension.Config().ChainID, hubChannel.ChannelID)
+	require.NoError(t, err)
+
+	unrelayedAcks, err = QueryUnrelayedAcks(ctx, dymension.CosmosChain, rollapp1.CosmosChain, hubChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayedAcks)
+	commitments, err := QueryPacketCommitments(ctx, dymension.CosmosChain, hubChannel.PortID, hubChannel.ChannelID)
+	require.NoError(t, err)
+	require.Empty(t, commitments)
+
+	// Send from the rollapp, the hub holds back the acknowledgement until the height of the packet is finalized
+	transferData = ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	tx, err = rollapp1.SendIBCTransfer(ctx, rollappChannel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+	rollappSequence := tx.Packet.Sequence
+
+	rollappHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+
+	unrelayed, err = QueryUnrelayedPackets(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{rollappSequence}, unrelayed)
+
+	err = manual.RelayPackets(ctx, eRep, ibcPath, rollapp1.Config().ChainID, rollappChannel.ChannelID)
+	require.NoError(t, err)
+
+	unrelayed, err = QueryUnrelayedPackets(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayed)
+	unrelayedAcks, err = QueryUnrelayedAcks(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayedAcks)
+
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+
+	// The finalization writes the acknowledgement and credits the transfer on the hub
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	unrelayedAcks, err = QueryUnrelayedAcks(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Equal(t, []uint64{rollappSequence}, unrelayedAcks)
+
+	err = manual.RelayAcks(ctx, eRep, ibcPath, rollapp1.Config().ChainID, rollappChannel.ChannelID)
+	require.NoError(t, err)
+
+	unrelayedAcks, err = QueryUnrelayedAcks(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Empty(t, unrelayedAcks)
+	commitments, err = QueryPacketCommitments(ctx, rollapp1.CosmosChain, rollappChannel.PortID, rollappChannel.ChannelID)
+	require.NoError(t, err)
+	require.Empty(t, commitments)
+}
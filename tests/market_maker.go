@@ -0,0 +1,342 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	dymensiontesting "github.com/decentrio/rollup-e2e-testing/dymension"
+)
+
+// MarketMakerStrategy decides which demand orders a MarketMaker fulfills.
+type MarketMakerStrategy struct {
+	// MinFeeRate is the minimum fee, as a share of the order amount (price plus fee), worth fulfilling an order for.
+	// A nil rate accepts any fee.
+	MinFeeRate sdk.Dec
+	// MinBalance is the balance of the order denom the market maker keeps, it skips orders that would leave less
+	MinBalance math.Int
+	// Budgets caps the total price the market maker pays for orders, per denom. Orders in a denom without a
+	// budget are skipped.
+	Budgets map[string]math.Int
+}
+
+// skipReason returns why an order should not be fulfilled, or an empty string if it should.
+func (s MarketMakerStrategy) skipReason(denom string, price, fee, balance, spent math.Int) string {
+	amount := price.Add(fee)
+	if !s.MinFeeRate.IsNil() && sdk.NewDecFromInt(fee).LT(s.MinFeeRate.MulInt(amount)) {
+		return fmt.Sprintf("fee %s%s is below %s of %s%s", fee, denom, s.MinFeeRate, amount, denom)
+	}
+	budget, ok := s.Budgets[denom]
+	if !ok {
+		return fmt.Sprintf("no budget for %s", denom)
+	}
+	if spent.Add(price).GT(budget) {
+		return fmt.Sprintf("price %s%s exceeds the remaining budget of %s%s", price, denom, budget.Sub(spent), denom)
+	}
+	minBalance := math.ZeroInt()
+	if !s.MinBalance.IsNil() {
+		minBalance = s.MinBalance
+	}
+	if balance.Sub(price).LT(minBalance) {
+		return fmt.Sprintf("price %s%s would leave the balance of %s%s below %s%s", price, denom, balance, denom, minBalance, denom)
+	}
+	return ""
+}
+
+// MarketMakerFill is a demand order fulfilled by a MarketMaker.
+type MarketMakerFill struct {
+	OrderID string
+	Denom   string
+	Price   math.Int
+	Fee     math.Int
+	// Status is the status of the packet of the order as of the last MarketMaker.Reconcile
+	Status string
+}
+
+// MarketMakerLedger records every decision of a MarketMaker.
+type MarketMakerLedger struct {
+	Fills []MarketMakerFill
+	// Skipped holds why each order the strategy rejected was skipped, by order id
+	Skipped map[string]string
+	// Failed holds the error of each fulfillment the hub rejected, by order id
+	Failed map[string]string
+}
+
+// Decisions returns the number of orders the market maker has made a decision on.
+func (l MarketMakerLedger) Decisions() int {
+	return len(l.Fills) + len(l.Skipped) + len(l.Failed)
+}
+
+// Spent returns the total price paid for the orders fulfilled in the denom.
+func (l MarketMakerLedger) Spent(denom string) math.Int {
+	spent := math.ZeroInt()
+	for _, fill := range l.Fills {
+		if fill.Denom == denom {
+			spent = spent.Add(fill.Price)
+		}
+	}
+	return spent
+}
+
+// PnL returns the realized profit and loss in the denom: the fee of every fill whose packet was finalized, minus
+// the price of every fill whose packet was reverted. Fills still pending are not accounted for.
+func (l MarketMakerLedger) PnL(denom string) math.Int {
+	pnl := math.ZeroInt()
+	for _, fill := range l.Fills {
+		if fill.Denom != denom {
+			continue
+		}
+		switch fill.Status {
+		case stateStatusFinalized:
+			pnl = pnl.Add(fill.Fee)
+		case stateStatusReverted:
+			pnl = pnl.Sub(fill.Price)
+		}
+	}
+	return pnl
+}
+
+func (l MarketMakerLedger) copy() MarketMakerLedger {
+	c := MarketMakerLedger{
+		Fills:   append([]MarketMakerFill(nil), l.Fills...),
+		Skipped: make(map[string]string, len(l.Skipped)),
+		Failed:  make(map[string]string, len(l.Failed)),
+	}
+	for id, reason := range l.Skipped {
+		c.Skipped[id] = reason
+	}
+	for id, reason := range l.Failed {
+		c.Failed[id] = reason
+	}
+	return c
+}
+
+// MarketMaker is a market maker bot. Once started it follows the eibc events of the hub in a goroutine and
+// fulfills the new demand orders its strategy accepts, until its context is cancelled or it is stopped.
+type MarketMaker struct {
+	hub      *dym_hub.DymHub
+	address  string
+	strategy MarketMakerStrategy
+
+	mu     sync.Mutex
+	ledger MarketMakerLedger
+	seen   map[string]bool
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMarketMaker creates a market maker fulfilling orders from the hub account with the given address.
+func NewMarketMaker(hub *dym_hub.DymHub, address string, strategy MarketMakerStrategy) *MarketMaker {
+	return &MarketMaker{
+		hub:      hub,
+		address:  address,
+		strategy: strategy,
+		ledger: MarketMakerLedger{
+			Skipped: make(map[string]string),
+			Failed:  make(map[string]string),
+		},
+		seen: make(map[string]bool),
+	}
+}
+
+// Start runs the market maker in a goroutine. Only the orders created from the current hub height on are considered.
+func (m *MarketMaker) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.done != nil {
+		return fmt.Errorf("market maker %s already started", m.address)
+	}
+
+	height, err := m.hub.Height(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get hub height: %w", err)
+	}
+
+	ctx, m.cancel = context.WithCancel(ctx)
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		m.run(ctx, height)
+	}()
+	return nil
+}
+
+// Stop cancels the market maker and waits for it to return. An order being fulfilled when it is stopped is
+// recorded as failed.
+func (m *MarketMaker) Stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.mu.Unlock()
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// Ledger returns a copy of the decisions made so far.
+func (m *MarketMaker) Ledger() MarketMakerLedger {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ledger.copy()
+}
+
+// WaitForDecisions waits until the market maker has made a decision on at least n orders.
+func (m *MarketMaker) WaitForDecisions(ctx context.Context, n int) (MarketMakerLedger, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultDemandOrderWaitTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(stateWatcherPollInterval)
+	defer ticker.Stop()
+	for {
+		ledger := m.Ledger()
+		if ledger.Decisions() >= n {
+			return ledger, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ledger, fmt.Errorf("timed out waiting for market maker %s to decide on %d orders, decided on %d: %w",
+				m.address, n, ledger.Decisions(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Reconcile updates the status of every fill from the hub and returns the ledger.
+func (m *MarketMaker) Reconcile(ctx context.Context) (MarketMakerLedger, error) {
+	ledger := m.Ledger()
+	statuses := make(map[string]string, len(ledger.Fills))
+	for _, fill := range ledger.Fills {
+		order, err := QueryDemandOrder(ctx, m.hub, fill.OrderID)
+		if err != nil {
+			return ledger, fmt.Errorf("failed to reconcile fill of %s: %w", fill.OrderID, err)
+		}
+		statuses[fill.OrderID] = order.PacketStatus
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, fill := range m.ledger.Fills {
+		if status, ok := statuses[fill.OrderID]; ok {
+			m.ledger.Fills[i].Status = status
+		}
+	}
+	return m.ledger.copy(), nil
+}
+
+// run follows the hub blocks from the given height. Failed queries are retried on the next tick, so only the
+// cancellation of the context stops it.
+func (m *MarketMaker) run(ctx context.Context, fromHeight uint64) {
+	ticker := time.NewTicker(stateWatcherPollInterval)
+	defer ticker.Stop()
+
+	next := fromHeight
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		height, err := m.hub.Height(ctx)
+		if err != nil {
+			continue
+		}
+		for ; next <= height && ctx.Err() == nil; next++ {
+			txs, err := m.hub.FindTxs(ctx, next)
+			if err != nil {
+				break
+			}
+			for _, tx := range txs {
+				for _, event := range tx.Events {
+					if event.Type != "eibc" {
+						continue
+					}
+					eibcEvent, err := dymensiontesting.MapToEibcEvent(event)
+					if err != nil {
+						continue
+					}
+					m.handle(ctx, eibcEvent)
+				}
+			}
+		}
+	}
+}
+
+// handle decides on a newly created demand order and fulfills it if the strategy accepts it.
+func (m *MarketMaker) handle(ctx context.Context, event dymensiontesting.EibcEvent) {
+	if event.IsFulfilled || !strings.EqualFold(event.PacketStatus, stateStatusPending) {
+		return
+	}
+	m.mu.Lock()
+	if m.seen[event.ID] {
+		m.mu.Unlock()
+		return
+	}
+	m.seen[event.ID] = true
+	m.mu.Unlock()
+
+	price, err := sdk.ParseCoinsNormalized(event.Price)
+	if err != nil {
+		m.fail(event.ID, fmt.Errorf("failed to parse price %q: %w", event.Price, err))
+		return
+	}
+	fee, err := sdk.ParseCoinsNormalized(event.Fee)
+	if err != nil || fee.Len() != 1 {
+		m.fail(event.ID, fmt.Errorf("failed to parse fee %q: %v", event.Fee, err))
+		return
+	}
+	denom := fee[0].Denom
+
+	balance, err := m.hub.GetBalance(ctx, m.address, denom)
+	if err != nil {
+		m.fail(event.ID, fmt.Errorf("failed to get balance: %w", err))
+		return
+	}
+
+	m.mu.Lock()
+	reason := m.strategy.skipReason(denom, price.AmountOf(denom), fee.AmountOf(denom), balance, m.ledger.Spent(denom))
+	if reason != "" {
+		m.ledger.Skipped[event.ID] = reason
+	}
+	m.mu.Unlock()
+	if reason != "" {
+		return
+	}
+
+	res, err := FulfillDemandOrder(ctx, m.hub, KeyringNode(m.hub.CosmosChain), event.ID, m.address)
+	if err != nil {
+		m.fail(event.ID, err)
+		return
+	}
+	// the fill is only booked once executed, it is rejected if another market maker fulfilled the order first
+	if res.Code != 0 {
+		m.fail(event.ID, fmt.Errorf("fulfillment rejected with %s error %d: %s", res.Codespace, res.Code, res.RawLog))
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ledger.Fills = append(m.ledger.Fills, MarketMakerFill{
+		OrderID: event.ID,
+		Denom:   denom,
+		Price:   price.AmountOf(denom),
+		Fee:     fee.AmountOf(denom),
+		Status:  stateStatusPending,
+	})
+}
+
+func (m *MarketMaker) fail(orderID string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ledger.Failed[orderID] = err.Error()
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	clienttypes "github.com/cosmos/ibc-go/v6/modules/core/02-client/types"
+	ibctm "github.com/cosmos/ibc-go/v6/modules/light-clients/07-tendermint/types"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	tmproto "github.com/tendermint/tendermint/proto/tendermint/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// sequencerKeyFiles are the keys a rollapp node signs its blocks with as the sequencer
+var sequencerKeyFiles = []string{"config/node_key.json", "config/priv_validator_key.json"}
+
+// StartRogueSequencer turns the rollapp node into a second sequencer running with the key of the sequencer. The
+// node is cut off from the sequencer and the other rollapp nodes, so it builds its own blocks on top of the state
+// it had synced: a fork of the rollapp whose headers conflict with the ones of the sequencer, signed by the same key.
+func StartRogueSequencer(ctx context.Context, faults *NetworkFaults, sequencer, rogue *cosmos.Node, others ...*cosmos.Node) error {
+	if err := StopNode(ctx, rogue); err != nil {
+		return err
+	}
+	for _, keyFile := range sequencerKeyFiles {
+		key, err := sequencer.ReadFile(ctx, keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read %s of %s: %w", keyFile, sequencer.Name(), err)
+		}
+		if err := rogue.WriteFile(ctx, key, keyFile); err != nil {
+			return fmt.Errorf("failed to write %s of %s: %w", keyFile, rogue.Name(), err)
+		}
+	}
+
+	honest := NodeContainerIDs(append([]*cosmos.Node{sequencer}, others...)...)
+	if err := faults.Partition(ctx, NodeContainerIDs(rogue), honest); err != nil {
+		return fmt.Errorf("failed to cut %s off from the rollapp: %w", rogue.Name(), err)
+	}
+	return RestartNode(ctx, rogue)
+}
+
+// ConflictingHeaders returns the headers of the honest and the rogue node at the same height, as light client
+// updates on top of the consensus state the client holds at the trusted height. Both nodes sign with the key of the
+// sequencer, so the headers make up misbehaviour as soon as the rogue node has forked below the height.
+func ConflictingHeaders(ctx context.Context, honest, rogue *cosmos.Node, height int64, trustedHeight clienttypes.Height) (*ibctm.Header, *ibctm.Header, error) {
+	// the consensus state at the trusted height commits to the validators of the next height
+	trustedValidators, err := validatorSet(ctx, honest, int64(trustedHeight.RevisionHeight)+1)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := make([]*ibctm.Header, 0, 2)
+	for _, node := range []*cosmos.Node{honest, rogue} {
+		commit, err := node.Client.Commit(ctx, &height)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get commit %d of %s: %w", height, node.Name(), err)
+		}
+		validators, err := validatorSet(ctx, node, height)
+		if err != nil {
+			return nil, nil, err
+		}
+		headers = append(headers, &ibctm.Header{
+			SignedHeader:      commit.SignedHeader.ToProto(),
+			ValidatorSet:      validators,
+			TrustedHeight:     trustedHeight,
+			TrustedValidators: trustedValidators,
+		})
+	}
+
+	if bytes.Equal(headers[0].SignedHeader.Commit.BlockID.Hash, headers[1].SignedHeader.Commit.BlockID.Hash) {
+		return nil, nil, fmt.Errorf("%s and %s agree on block %d, the rogue node forked above it", honest.Name(), rogue.Name(), height)
+	}
+	return headers[0], headers[1], nil
+}
+
+// validatorSet returns the validators of the node at the height.
+func validatorSet(ctx context.Context, node *cosmos.Node, height int64) (*tmproto.ValidatorSet, error) {
+	res, err := node.Client.Validators(ctx, &height, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get validators %d of %s: %w", height, node.Name(), err)
+	}
+	validators, err := tmtypes.NewValidatorSet(res.Validators).ToProto()
+	if err != nil {
+		return nil, fmt.Errorf("invalid validators %d of %s: %w", height, node.Name(), err)
+	}
+	return validators, nil
+}
+
+// SubmitMisbehaviourAsUser submits the conflicting headers as misbehaviour of the client on the chain from the key
+// of a user, bypassing the relayer. The chain freezes the client once it has verified both headers.
+func SubmitMisbehaviourAsUser(ctx context.Context, chain *cosmos.CosmosChain, keyName, clientID string, header1, header2 *ibctm.Header) error {
+	misbehaviour, err := chain.Config().EncodingConfig.Codec.MarshalInterfaceJSON(ibctm.NewMisbehaviour(clientID, header1, header2))
+	if err != nil {
+		return fmt.Errorf("failed to marshal misbehaviour of client %s: %w", clientID, err)
+	}
+
+	txHash, err := chain.GetNode().ExecTx(ctx, keyName, "ibc", "client", "misbehaviour", string(misbehaviour), "--gas=auto")
+	if err != nil {
+		return fmt.Errorf("failed to submit misbehaviour of client %s: %w", clientID, err)
+	}
+	res, err := TxResult(chain, txHash)
+	if err != nil {
+		return err
+	}
+	if res.Code != 0 {
+		return fmt.Errorf("misbehaviour of client %s rejected with %s error %d: %s", clientID, res.Codespace, res.Code, res.RawLog)
+	}
+	return nil
+}
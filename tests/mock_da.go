@@ -0,0 +1,409 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/client"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The mock DA serves the dymint gRPC DA protocol (dalc.DALCService), so dymint can be pointed at it with the
+// "grpc" DA layer. The messages are small enough to be encoded by hand, which keeps dymint out of the dependencies.
+const (
+	mockDAServiceName = "dalc.DALCService"
+	mockDALayer       = "grpc"
+
+	// status codes of a DA response
+	mockDAStatusSuccess = 1
+	mockDAStatusError   = 3
+
+	mockDAPollInterval = time.Second
+)
+
+// MockDAFault is a failure the mock DA injects into the batches submitted to it.
+type MockDAFault string
+
+const (
+	// MockDANoFault persists every batch
+	MockDANoFault MockDAFault = ""
+	// MockDAReject answers every batch with an error without persisting it
+	MockDAReject MockDAFault = "reject"
+	// MockDADelay persists every batch, but only answers after the configured delay
+	MockDADelay MockDAFault = "delay"
+	// MockDADrop never answers, the batch is lost once the submitter gives up or the fault is cleared
+	MockDADrop MockDAFault = "drop"
+)
+
+// MockDASubmission is a batch submitted to the mock DA.
+type MockDASubmission struct {
+	StartHeight uint64
+	EndHeight   uint64
+	// Fault is the fault injected into the submission
+	Fault MockDAFault
+	// Persisted is set once the batch is stored, at DA height DAHeight
+	Persisted   bool
+	DAHeight    uint64
+	ReceivedAt  time.Time
+	PersistedAt time.Time
+}
+
+func (s MockDASubmission) String() string {
+	return fmt.Sprintf("rollapp heights %d-%d (fault %q, persisted %t at DA height %d)",
+		s.StartHeight, s.EndHeight, s.Fault, s.Persisted, s.DAHeight)
+}
+
+// MockDA is a DA layer served from the test process to the rollapps on the Docker network. It records every
+// batch submitted to it and injects the configured fault.
+type MockDA struct {
+	host   string
+	port   int
+	server *grpc.Server
+
+	mu          sync.Mutex
+	fault       MockDAFault
+	delay       time.Duration
+	release     chan struct{}
+	submissions []MockDASubmission
+	// batches are the raw persisted batches by DA height
+	batches  map[uint64][][]byte
+	daHeight uint64
+}
+
+// StartMockDA starts a mock DA reachable from the containers of the Docker network, and stops it on cleanup.
+func StartMockDA(t *testing.T, ctx context.Context, cli *client.Client, networkID string) (*MockDA, error) {
+	network, err := cli.NetworkInspect(ctx, networkID, types.NetworkInspectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect docker network %s: %w", networkID, err)
+	}
+	if len(network.IPAM.Config) == 0 || network.IPAM.Config[0].Gateway == "" {
+		return nil, fmt.Errorf("docker network %s has no gateway to reach the mock DA", networkID)
+	}
+
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for the mock DA: %w", err)
+	}
+
+	m := &MockDA{
+		host:    network.IPAM.Config[0].Gateway,
+		port:    listener.Addr().(*net.TCPAddr).Port,
+		server:  grpc.NewServer(grpc.ForceServerCodec(rawCodec{})),
+		release: make(chan struct{}),
+		batches: make(map[uint64][][]byte),
+	}
+	m.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: mockDAServiceName,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "SubmitBatch", Handler: m.handler(m.submitBatch)},
+			{MethodName: "CheckBatchAvailability", Handler: m.handler(m.checkBatchAvailability)},
+			{MethodName: "RetrieveBatches", Handler: m.handler(m.retrieveBatches)},
+		},
+	}, m)
+
+	go func() {
+		_ = m.server.Serve(listener)
+	}()
+	t.Cleanup(m.Stop)
+	return m, nil
+}
+
+// DALayer is the dymint DA layer to point a rollapp at the mock DA.
+func (m *MockDA) DALayer() string {
+	return mockDALayer
+}
+
+// DAConfig is the dymint DA config to point a rollapp at the mock DA.
+func (m *MockDA) DAConfig() string {
+	return fmt.Sprintf(`{"host":"%s","port":%d}`, m.host, m.port)
+}
+
+// SetFault injects the fault into every batch submitted from now on. The delay only applies to MockDADelay.
+// Submissions held by the previous fault are released.
+func (m *MockDA) SetFault(fault MockDAFault, delay time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fault = fault
+	m.delay = delay
+	close(m.release)
+	m.release = make(chan struct{})
+}
+
+// ClearFault persists every batch submitted from now on.
+func (m *MockDA) ClearFault() {
+	m.SetFault(MockDANoFault, 0)
+}
+
+// Stop stops serving, releasing the submissions still held.
+func (m *MockDA) Stop() {
+	m.ClearFault()
+	m.server.Stop()
+}
+
+// Submissions returns every batch submitted so far, in the order they were received.
+func (m *MockDA) Submissions() []MockDASubmission {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockDASubmission(nil), m.submissions...)
+}
+
+// WaitForSubmissions waits until n batches in total were submitted, and returns them.
+func (m *MockDA) WaitForSubmissions(ctx context.Context, n int) ([]MockDASubmission, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(mockDAPollInterval)
+	defer ticker.Stop()
+
+	for {
+		submissions := m.Submissions()
+		if len(submissions) >= n {
+			return submissions, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for %d batches submitted to the mock DA, got %d: %w", n, len(submissions), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Unpersisted returns the state updates whose batch the mock DA never persisted.
+func (m *MockDA) Unpersisted(updates []StateUpdate) []StateUpdate {
+	persisted := make(map[[2]uint64]bool)
+	for _, s := range m.Submissions() {
+		if s.Persisted {
+			persisted[[2]uint64{s.StartHeight, s.EndHeight}] = true
+		}
+	}
+
+	var unpersisted []StateUpdate
+	for _, u := range updates {
+		if !persisted[[2]uint64{u.StartHeight, u.EndHeight()}] {
+			unpersisted = append(unpersisted, u)
+		}
+	}
+	return unpersisted
+}
+
+func (m *MockDA) submitBatch(ctx context.Context, req []byte) ([]byte, error) {
+	batch, err := decodeBytesField(req, 1)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid batch submission: %s", err)
+	}
+	startHeight, endHeight, err := decodeBatchHeights(batch)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid batch: %s", err)
+	}
+
+	m.mu.Lock()
+	fault, delay, release := m.fault, m.delay, m.release
+	i := len(m.submissions)
+	m.submissions = append(m.submissions, MockDASubmission{
+		StartHeight: startHeight,
+		EndHeight:   endHeight,
+		Fault:       fault,
+		ReceivedAt:  time.Now(),
+	})
+	m.mu.Unlock()
+
+	switch fault {
+	case MockDAReject:
+		return encodeDAResponse(1, mockDAStatusError, "batch rejected by the mock DA", 0), nil
+	case MockDADrop:
+		select {
+		case <-ctx.Done():
+		case <-release:
+		}
+		return nil, status.Error(codes.Unavailable, "batch dropped by the mock DA")
+	case MockDADelay:
+		select {
+		case <-ctx.Done():
+			return nil, status.FromContextError(ctx.Err()).Err()
+		case <-release:
+		case <-time.After(delay):
+		}
+	}
+
+	m.mu.Lock()
+	m.daHeight++
+	daHeight := m.daHeight
+	m.batches[daHeight] = append(m.batches[daHeight], batch)
+	m.submissions[i].Persisted = true
+	m.submissions[i].DAHeight = daHeight
+	m.submissions[i].PersistedAt = time.Now()
+	m.mu.Unlock()
+
+	return encodeDAResponse(1, mockDAStatusSuccess, "batch persisted", daHeight), nil
+}
+
+func (m *MockDA) checkBatchAvailability(_ context.Context, req []byte) ([]byte, error) {
+	daHeight, err := decodeVarintField(req, 1)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid availability check: %s", err)
+	}
+
+	m.mu.Lock()
+	available := len(m.batches[daHeight]) > 0
+	m.mu.Unlock()
+
+	res := encodeDAResponse(1, mockDAStatusSuccess, "", daHeight)
+	res = protowire.AppendTag(res, 2, protowire.VarintType)
+	return protowire.AppendVarint(res, protowire.EncodeBool(available)), nil
+}
+
+func (m *MockDA) retrieveBatches(_ context.Context, req []byte) ([]byte, error) {
+	daHeight, err := decodeVarintField(req, 1)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid batch retrieval: %s", err)
+	}
+
+	m.mu.Lock()
+	batches := m.batches[daHeight]
+	m.mu.Unlock()
+
+	res := encodeDAResponse(1, mockDAStatusSuccess, "", daHeight)
+	for _, batch := range batches {
+		res = protowire.AppendTag(res, 2, protowire.BytesType)
+		res = protowire.AppendBytes(res, batch)
+	}
+	return res, nil
+}
+
+func (m *MockDA) handler(method func(ctx context.Context, req []byte) ([]byte, error)) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	return func(_ any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		var req rawMessage
+		if err := dec(&req); err != nil {
+			return nil, err
+		}
+		res, err := method(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return (*rawMessage)(&res), nil
+	}
+}
+
+// rawMessage is a protobuf message the mock DA encodes and decodes itself.
+type rawMessage []byte
+
+// rawCodec passes the protobuf messages through as raw bytes.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return *msg, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*msg = append((*msg)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
+
+// encodeDAResponse encodes a dalc.DAResponse as field num of the enclosing message.
+func encodeDAResponse(num protowire.Number, code uint64, message string, daHeight uint64) []byte {
+	var res []byte
+	res = protowire.AppendTag(res, 1, protowire.VarintType)
+	res = protowire.AppendVarint(res, code)
+	if message != "" {
+		res = protowire.AppendTag(res, 2, protowire.BytesType)
+		res = protowire.AppendString(res, message)
+	}
+	res = protowire.AppendTag(res, 3, protowire.VarintType)
+	res = protowire.AppendVarint(res, daHeight)
+
+	var msg []byte
+	msg = protowire.AppendTag(msg, num, protowire.BytesType)
+	return protowire.AppendBytes(msg, res)
+}
+
+// decodeBatchHeights returns the start and end heights of a dymint.Batch.
+func decodeBatchHeights(batch []byte) (uint64, uint64, error) {
+	startHeight, err := decodeVarintField(batch, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	endHeight, err := decodeVarintField(batch, 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	return startHeight, endHeight, nil
+}
+
+// decodeVarintField returns the last value of the varint field num of the message, 0 if it is not set.
+func decodeVarintField(msg []byte, num protowire.Number) (uint64, error) {
+	var value uint64
+	err := rangeFields(msg, func(n protowire.Number, typ protowire.Type, field []byte) error {
+		if n != num || typ != protowire.VarintType {
+			return nil
+		}
+		v, l := protowire.ConsumeVarint(field)
+		if l < 0 {
+			return protowire.ParseError(l)
+		}
+		value = v
+		return nil
+	})
+	return value, err
+}
+
+// decodeBytesField returns the last value of the length-delimited field num of the message, nil if it is not set.
+func decodeBytesField(msg []byte, num protowire.Number) ([]byte, error) {
+	var value []byte
+	err := rangeFields(msg, func(n protowire.Number, typ protowire.Type, field []byte) error {
+		if n != num || typ != protowire.BytesType {
+			return nil
+		}
+		v, l := protowire.ConsumeBytes(field)
+		if l < 0 {
+			return protowire.ParseError(l)
+		}
+		value = v
+		return nil
+	})
+	return value, err
+}
+
+// rangeFields calls fn with the number, type and encoded value of every field of the message.
+func rangeFields(msg []byte, fn func(num protowire.Number, typ protowire.Type, field []byte) error) error {
+	for len(msg) > 0 {
+		num, typ, l := protowire.ConsumeTag(msg)
+		if l < 0 {
+			return protowire.ParseError(l)
+		}
+		msg = msg[l:]
+		l = protowire.ConsumeFieldValue(num, typ, msg)
+		if l < 0 {
+			return protowire.ParseError(l)
+		}
+		if err := fn(num, typ, msg[:l]); err != nil {
+			return err
+		}
+		msg = msg[l:]
+	}
+	return nil
+}
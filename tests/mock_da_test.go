@@ -0,0 +1,413 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// This test case points the rollapp at a mock DA and injects DA faults. While the mock DA rejects or drops its
+// batches the rollapp keeps resubmitting them and the hub gets no state update, a delayed batch only reaches the
+// hub once persisted, and every state update on the hub is for a batch the mock DA actually persisted
+func TestMockDAFaults_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	// The mock DA has to be reachable from the Docker network before the rollapp is configured
+	client, network := test.DockerSetup(t)
+	mockDA, err := StartMockDA(t, ctx, client, network)
+	require.NoError(t, err)
+
+	dymintConfig := NewDymintConfig(t, "rollappevm_1234-1").WithMockDA(mockDA)
+	configFileOverrides := dymintConfig.ConfigFileOverrides()
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymensionGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	_ = test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	// The rollapp submits its batches to the mock DA
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	batchCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForNextBatch(batchCtx)
+	require.NoError(t, err)
+	require.NotEmpty(t, mockDA.Submissions(), "rollapp did not submit its batches to the mock DA")
+
+	for _, fault := range []MockDAFault{MockDAReject, MockDADrop} {
+		mockDA.SetFault(fault, 0)
+		submitted := len(mockDA.Submissions())
+
+		// let a batch persisted before the fault reach the hub
+		_, err = mockDA.WaitForSubmissions(batchCtx, submitted+1)
+		require.NoError(t, err)
+		index, err := stateWatcher.LatestIndex(ctx)
+		require.NoError(t, err)
+
+		// the rollapp keeps resubmitting, the hub gets nothing
+		if fault == MockDAReject {
+			_, err = mockDA.WaitForSubmissions(batchCtx, submitted+3)
+			require.NoError(t, err)
+		} else {
+			err = testutil.WaitForBlocks(ctx, 20, dymension)
+			require.NoError(t, err)
+		}
+		stuck, err := stateWatcher.LatestIndex(ctx)
+		require.NoError(t, err)
+		require.Equal(t, index, stuck, "hub got a state update while the mock DA injects %q", fault)
+
+		// the rollapp recovers once the fault is cleared
+		mockDA.ClearFault()
+		_, err = stateWatcher.WaitForIndex(batchCtx, stuck+1)
+		require.NoError(t, err)
+	}
+
+	// A delayed batch only reaches the hub once it is persisted
+	delay := 30 * time.Second
+	mockDA.SetFault(MockDADelay, delay)
+	submitted := len(mockDA.Submissions())
+	submissions, err := mockDA.WaitForSubmissions(batchCtx, submitted+1)
+	require.NoError(t, err)
+	delayed := submissions[submitted]
+	require.Equal(t, MockDADelay, delayed.Fault)
+	index, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+
+	update, err := stateWatcher.WaitForIndex(batchCtx, index+1)
+	require.NoError(t, err)
+	mockDA.ClearFault()
+	delayed = mockDA.Submissions()[submitted]
+	require.True(t, delayed.Persisted, "batch %s reached the hub before it was persisted", delayed)
+	require.GreaterOrEqual(t, delayed.PersistedAt.Sub(delayed.ReceivedAt), delay)
+	require.Equal(t, delayed.StartHeight, update.StartHeight)
+
+	// Every state update on the hub is for a persisted batch
+	latest, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+	updates := make([]StateUpdate, 0, latest)
+	for i := uint64(1); i <= latest; i++ {
+		update, err := stateWatcher.StateInfo(ctx, i)
+		require.NoError(t, err)
+		updates = append(updates, *update)
+	}
+	require.Empty(t, mockDA.Unpersisted(updates), "hub has state updates for batches the DA did not persist")
+
+	for _, s := range mockDA.Submissions() {
+		t.Logf("mock DA submission: %s", s)
+	}
+}
+
+func TestMockDAFaults_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	// The mock DA has to be reachable from the Docker network before the rollapp is configured
+	client, network := test.DockerSetup(t)
+	mockDA, err := StartMockDA(t, ctx, client, network)
+	require.NoError(t, err)
+
+	dymintConfig := NewDymintConfig(t, "rollappwasm_1234-1").WithMockDA(mockDA)
+	configFileOverrides := dymintConfig.ConfigFileOverrides()
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 0
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymensionGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	_ = test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	// The rollapp submits its batches to the mock DA
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	batchCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	_, err = stateWatcher.WaitForNextBatch(batchCtx)
+	require.NoError(t, err)
+	require.NotEmpty(t, mockDA.Submissions(), "rollapp did not submit its batches to the mock DA")
+
+	for _, fault := range []MockDAFault{MockDAReject, MockDADrop} {
+		mockDA.SetFault(fault, 0)
+		submitted := len(mockDA.Submissions())
+
+		// let a batch persisted before the fault reach the hub
+		_, err = mockDA.WaitForSubmissions(batchCtx, submitted+1)
+		require.NoError(t, err)
+		index, err := stateWatcher.LatestIndex(ctx)
+		require.NoError(t, err)
+
+		// the rollapp keeps resubmitting, the hub gets nothing
+		if fault == MockDAReject {
+			_, err = mockDA.WaitForSubmissions(batchCtx, submitted+3)
+			require.NoError(t, err)
+		} else {
+			err = testutil.WaitForBlocks(ctx, 20, dymension)
+			require.NoError(t, err)
+		}
+		stuck, err := stateWatcher.LatestIndex(ctx)
+		require.NoError(t, err)
+		require.Equal(t, index, stuck, "hub got a state update while the mock DA injects %q", fault)
+
+		// the rollapp recovers once the fault is cleared
+		mockDA.ClearFault()
+		_, err = stateWatcher.WaitForIndex(batchCtx, stuck+1)
+		require.NoError(t, err)
+	}
+
+	// A delayed batch only reaches the hub once it is persisted
+	delay := 30 * time.Second
+	mockDA.SetFault(MockDADelay, delay)
+	submitted := len(mockDA.Submissions())
+	submissions, err := mockDA.WaitForSubmissions(batchCtx, submitted+1)
+	require.NoError(t, err)
+	delayed := submissions[submitted]
+	require.Equal(t, MockDADelay, delayed.Fault)
+	index, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+
+	update, err := stateWatcher.WaitForIndex(batchCtx, index+1)
+	require.NoError(t, err)
+	mockDA.ClearFault()
+	delayed = mockDA.Submissions()[submitted]
+	require.True(t, delayed.Persisted, "batch %s reached the hub before it was persisted", delayed)
+	require.GreaterOrEqual(t, delayed.PersistedAt.Sub(delayed.ReceivedAt), delay)
+	require.Equal(t, delayed.StartHeight, update.StartHeight)
+
+	// Every state update on the hub is for a persisted batch
+	latest, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+	updates := make([]StateUpdate, 0, latest)
+	for i := uint64(1); i <= latest; i++ {
+		update, err := stateWatcher.StateInfo(ctx, i)
+		require.NoError(t, err)
+		updates = append(updates, *update)
+	}
+	require.Empty(t, mockDA.Unpersisted(updates), "hub has state updates for batches the DA did not persist")
+
+	for _, s := range mockDA.Submissions() {
+		t.Logf("mock DA submission: %s", s)
+	}
+}
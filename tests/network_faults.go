@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/dockerutil"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/client"
+)
+
+// NetworkFaults injects network faults between the containers of a test by partitioning them. Every fault is undone
+// when the test ends, so a failing test does not leave its containers unreachable for the cleanup.
+type NetworkFaults struct {
+	cli       *client.Client
+	networkID string
+	testName  string
+
+	mu sync.Mutex
+	// disconnected holds the endpoint settings of the isolated containers cut off from the test network, to
+	// reconnect them under the same names
+	disconnected map[string]*network.EndpointSettings
+	// partitions holds the networks created to partition containers, with the containers joined to each
+	partitions map[string][]string
+}
+
+// NewNetworkFaults returns the fault injector of the test network set up by test.DockerSetup.
+func NewNetworkFaults(t *testing.T, cli *client.Client, networkID string) *NetworkFaults {
+	f := &NetworkFaults{
+		cli:          cli,
+		networkID:    networkID,
+		testName:     t.Name(),
+		disconnected: make(map[string]*network.EndpointSettings),
+		partitions:   make(map[string][]string),
+	}
+	t.Cleanup(func() {
+		if err := f.Heal(context.Background()); err != nil {
+			t.Logf("an error occurred while healing the network: %s", err)
+		}
+	})
+	return f
+}
+
+// NodeContainerIDs returns the container IDs of the nodes, to pass them to NetworkFaults.
+func NodeContainerIDs(nodes ...*cosmos.Node) []string {
+	ids := make([]string, len(nodes))
+	for i, node := range nodes {
+		ids[i] = node.ContainerID()
+	}
+	return ids
+}
+
+// Partition cuts the isolated containers off from the cut ones. The isolated containers are moved to a network of
+// their own, joined by every other container of the test network except the cut ones, so both sides keep reaching
+// the rest of the test. Containers created afterwards, such as the ones running CLI queries, only join the test
+// network and do not reach the isolated containers until Heal.
+func (f *NetworkFaults) Partition(ctx context.Context, isolated, cut []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	testNetwork, err := f.cli.NetworkInspect(ctx, f.networkID, types.NetworkInspectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to inspect test network: %w", err)
+	}
+
+	excluded := make(map[string]bool, len(isolated)+len(cut))
+	for _, id := range append(isolated, cut...) {
+		excluded[id] = true
+	}
+	var bridged []string
+	for id := range testNetwork.Containers {
+		if !excluded[id] {
+			bridged = append(bridged, id)
+		}
+	}
+
+	name := fmt.Sprintf("%s-partition-%s", testNetwork.Name, dockerutil.RandLowerCaseLetterString(5))
+	partition, err := f.cli.NetworkCreate(ctx, name, types.NetworkCreate{
+		CheckDuplicate: true,
+		Labels:         map[string]string{dockerutil.CleanupLabel: f.testName},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create partition network: %w", err)
+	}
+	f.partitions[partition.ID] = nil
+
+	for _, id := range append(isolated, bridged...) {
+		endpoint, err := f.endpointSettings(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := f.cli.NetworkConnect(ctx, partition.ID, id, &network.EndpointSettings{Aliases: endpoint.Aliases}); err != nil {
+			return fmt.Errorf("failed to join container %s to the partition: %w", id, err)
+		}
+		f.partitions[partition.ID] = append(f.partitions[partition.ID], id)
+	}
+
+	for _, id := range isolated {
+		if _, found := f.disconnected[id]; found {
+			continue
+		}
+		endpoint, err := f.endpointSettings(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := f.cli.NetworkDisconnect(ctx, f.networkID, id, true); err != nil {
+			return fmt.Errorf("failed to disconnect container %s: %w", id, err)
+		}
+		f.disconnected[id] = endpoint
+	}
+	return nil
+}
+
+// Heal undoes every fault: it joins the isolated containers back to the test network and removes the partition
+// networks. It keeps going on errors, returning them all.
+func (f *NetworkFaults) Heal(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var errs []error
+	// reconnect before leaving the partitions, so the isolated containers are never left without a network
+	for id, endpoint := range f.disconnected {
+		if err := f.cli.NetworkConnect(ctx, f.networkID, id, endpoint); err != nil {
+			errs = append(errs, fmt.Errorf("failed to reconnect container %s: %w", id, err))
+			continue
+		}
+		delete(f.disconnected, id)
+	}
+	for partitionID, members := range f.partitions {
+		for _, id := range members {
+			if err := f.cli.NetworkDisconnect(ctx, partitionID, id, true); err != nil {
+				errs = append(errs, fmt.Errorf("failed to leave partition with container %s: %w", id, err))
+			}
+		}
+		if err := f.cli.NetworkRemove(ctx, partitionID); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove partition network %s: %w", partitionID, err))
+			continue
+		}
+		delete(f.partitions, partitionID)
+	}
+	return errors.Join(errs...)
+}
+
+// endpointSettings returns the settings of the container on the test network, with its container and host names
+// as aliases so it resolves under them on any network it joins.
+func (f *NetworkFaults) endpointSettings(ctx context.Context, containerID string) (*network.EndpointSettings, error) {
+	info, err := f.cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect container %s: %w", containerID, err)
+	}
+
+	aliases := []string{strings.TrimPrefix(info.Name, "/")}
+	if info.Config != nil && info.Config.Hostname != "" {
+		aliases = append(aliases, info.Config.Hostname)
+	}
+	if info.NetworkSettings != nil {
+		for _, endpoint := range info.NetworkSettings.Networks {
+			if endpoint.NetworkID == f.networkID {
+				aliases = append(aliases, endpoint.Aliases...)
+			}
+		}
+	}
+	return &network.EndpointSettings{Aliases: aliases}, nil
+}
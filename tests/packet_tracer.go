@@ -0,0 +1,339 @@
+package tests
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	abci "github.com/tendermint/tendermint/abci/types"
+)
+
+const (
+	packetEventSend     = "send_packet"
+	packetEventWriteAck = "write_acknowledgement"
+	packetEventTimeout  = "timeout_packet"
+
+	// maxPacketHops bounds how many times a packet is followed through forwarding chains
+	maxPacketHops = 4
+	// packetSearchPageSize is the number of results of a tx or block search, far more than a packet produces
+	packetSearchPageSize = 100
+)
+
+// PacketID identifies an IBC packet by its ends and sequence.
+type PacketID struct {
+	SrcPort    string
+	SrcChannel string
+	DstPort    string
+	DstChannel string
+	Sequence   uint64
+}
+
+func (p PacketID) String() string {
+	return fmt.Sprintf("%s/%s#%d -> %s/%s", p.SrcPort, p.SrcChannel, p.Sequence, p.DstPort, p.DstChannel)
+}
+
+// PacketEvent is a step of the lifecycle of a packet on a chain.
+type PacketEvent struct {
+	ChainID string
+	Type    string
+	Height  int64
+	Time    time.Time
+	// TxHash is empty for the events emitted at the beginning or end of a block, such as the acknowledgements the
+	// hub writes once a rollapp height is finalized
+	TxHash string
+	Packet PacketID
+	// Ack is the acknowledgement written, for write_acknowledgement events
+	Ack string
+}
+
+// PacketTrace is the timeline of a packet across chains, with the packets it was forwarded as.
+type PacketTrace struct {
+	Events []PacketEvent
+}
+
+// Has returns whether the trace holds an event of the type.
+func (tr *PacketTrace) Has(eventType string) bool {
+	for _, event := range tr.Events {
+		if event.Type == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Types returns the types of the events of the trace, in order.
+func (tr *PacketTrace) Types() []string {
+	types := make([]string, len(tr.Events))
+	for i, event := range tr.Events {
+		types[i] = event.Type
+	}
+	return types
+}
+
+// String prints the timeline of the packet, one event per line.
+func (tr *PacketTrace) String() string {
+	var b strings.Builder
+	for _, event := range tr.Events {
+		fmt.Fprintf(&b, "%s %-16s height %-6d %-22s %s", event.Time.Format(time.RFC3339Nano), event.ChainID, event.Height, event.Type, event.Packet)
+		if event.TxHash != "" {
+			fmt.Fprintf(&b, " tx %s", event.TxHash)
+		} else {
+			b.WriteString(" in block events")
+		}
+		if event.Ack != "" {
+			fmt.Fprintf(&b, " ack %s", event.Ack)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// TracePacket follows the packets sent by the transaction on src through every chain given: their receipt, the
+// acknowledgement written, and the acknowledgement or timeout back on the chain that sent them. Packets forwarded by
+// a chain on receipt, as packet forward middleware does, are followed as well.
+func TracePacket(ctx context.Context, src *cosmos.CosmosChain, txHash string, chains ...*cosmos.CosmosChain) (*PacketTrace, error) {
+	hash, err := hex.DecodeString(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx hash %s: %w", txHash, err)
+	}
+	res, err := src.GetNode().Client.Tx(ctx, hash, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tx %s on %s: %w", txHash, src.Config().ChainID, err)
+	}
+
+	tracer := &packetTracer{
+		chains: append([]*cosmos.CosmosChain{src}, chains...),
+		times:  make(map[string]map[int64]time.Time),
+		trace:  &PacketTrace{},
+	}
+	sent := tracedTx{height: res.Height, hash: txHash, events: res.TxResult.Events}
+	if err := tracer.follow(ctx, src, sent, 0); err != nil {
+		return nil, err
+	}
+
+	// events at the same time keep the order they were found in, which follows the packet
+	sort.SliceStable(tracer.trace.Events, func(i, j int) bool {
+		return tracer.trace.Events[i].Time.Before(tracer.trace.Events[j].Time)
+	})
+	return tracer.trace, nil
+}
+
+// TracePacketOnFailure logs the trace of the packets sent by the transaction if the test fails, so a transfer that
+// does not show up can be followed without reading the logs of every container.
+func TracePacketOnFailure(t *testing.T, ctx context.Context, src *cosmos.CosmosChain, txHash string, chains ...*cosmos.CosmosChain) {
+	t.Cleanup(func() {
+		if !t.Failed() {
+			return
+		}
+		trace, err := TracePacket(ctx, src, txHash, chains...)
+		if err != nil {
+			t.Logf("failed to trace the packets of tx %s: %s", txHash, err)
+			return
+		}
+		t.Logf("packets of tx %s on %s:\n%s", txHash, src.Config().ChainID, trace)
+	})
+}
+
+type packetTracer struct {
+	chains []*cosmos.CosmosChain
+	// times caches the block times by chain and height
+	times map[string]map[int64]time.Time
+	trace *PacketTrace
+}
+
+// tracedTx is a transaction, or the events of a block outside of transactions when hash is empty.
+type tracedTx struct {
+	height int64
+	hash   string
+	events []abci.Event
+}
+
+// follow traces the packets sent in the transaction on the chain.
+func (p *packetTracer) follow(ctx context.Context, chain *cosmos.CosmosChain, sent tracedTx, hops int) error {
+	for _, packet := range packetsOf(sent.events, packetEventSend) {
+		if err := p.record(ctx, chain, sent, packetEventSend, packet); err != nil {
+			return err
+		}
+		if hops >= maxPacketHops {
+			continue
+		}
+		if err := p.followPacket(ctx, chain, packet, hops); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// followPacket traces a packet sent by the chain.
+func (p *packetTracer) followPacket(ctx context.Context, src *cosmos.CosmosChain, packet PacketID, hops int) error {
+	for _, dst := range p.chains {
+		if dst == src {
+			continue
+		}
+		received, err := p.search(ctx, dst, packetEventRecv, packet)
+		if err != nil {
+			return err
+		}
+		if len(received) == 0 {
+			continue
+		}
+
+		for _, tx := range received {
+			if err := p.record(ctx, dst, tx, packetEventRecv, packet); err != nil {
+				return err
+			}
+			// a packet forwarded on receipt is sent in the same transaction
+			if err := p.follow(ctx, dst, tx, hops+1); err != nil {
+				return err
+			}
+		}
+
+		// the acknowledgement is written on receipt, or later, when the forwarded packet is acknowledged or the
+		// height of the packet finalized
+		written, err := p.search(ctx, dst, packetEventWriteAck, packet)
+		if err != nil {
+			return err
+		}
+		for _, tx := range written {
+			if err := p.record(ctx, dst, tx, packetEventWriteAck, packet); err != nil {
+				return err
+			}
+		}
+		break
+	}
+
+	for _, eventType := range []string{packetEventAck, packetEventTimeout} {
+		txs, err := p.search(ctx, src, eventType, packet)
+		if err != nil {
+			return err
+		}
+		for _, tx := range txs {
+			if err := p.record(ctx, src, tx, eventType, packet); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// search returns the transactions and blocks of the chain with an event of the type for the packet.
+func (p *packetTracer) search(ctx context.Context, chain *cosmos.CosmosChain, eventType string, packet PacketID) ([]tracedTx, error) {
+	query := fmt.Sprintf("%[1]s.packet_src_port='%[2]s' AND %[1]s.packet_src_channel='%[3]s' AND %[1]s.packet_dst_channel='%[4]s' AND %[1]s.packet_sequence='%[5]d'",
+		eventType, packet.SrcPort, packet.SrcChannel, packet.DstChannel, packet.Sequence)
+	client := chain.GetNode().Client
+	page, perPage := 1, packetSearchPageSize
+
+	txs, err := client.TxSearch(ctx, query, false, &page, &perPage, "asc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s txs on %s: %w", eventType, chain.Config().ChainID, err)
+	}
+	var found []tracedTx
+	for _, tx := range txs.Txs {
+		found = append(found, tracedTx{height: tx.Height, hash: strings.ToUpper(hex.EncodeToString(tx.Hash)), events: tx.TxResult.Events})
+	}
+
+	blocks, err := client.BlockSearch(ctx, query, &page, &perPage, "asc")
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s blocks on %s: %w", eventType, chain.Config().ChainID, err)
+	}
+	for _, block := range blocks.Blocks {
+		height := block.Block.Height
+		res, err := client.BlockResults(ctx, &height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch block results of %s at height %d: %w", chain.Config().ChainID, height, err)
+		}
+		events := append(append([]abci.Event{}, res.BeginBlockEvents...), res.EndBlockEvents...)
+		found = append(found, tracedTx{height: height, events: events})
+	}
+	return found, nil
+}
+
+// record adds the events of the type for the packet in the transaction to the trace.
+func (p *packetTracer) record(ctx context.Context, chain *cosmos.CosmosChain, tx tracedTx, eventType string, packet PacketID) error {
+	blockTime, err := p.blockTime(ctx, chain, tx.height)
+	if err != nil {
+		return err
+	}
+	for _, attrs := range packetAttributes(tx.events, eventType, &packet) {
+		p.trace.Events = append(p.trace.Events, PacketEvent{
+			ChainID: chain.Config().ChainID,
+			Type:    eventType,
+			Height:  tx.height,
+			Time:    blockTime,
+			TxHash:  tx.hash,
+			Packet:  packet,
+			Ack:     attrs["packet_ack"],
+		})
+	}
+	return nil
+}
+
+func (p *packetTracer) blockTime(ctx context.Context, chain *cosmos.CosmosChain, height int64) (time.Time, error) {
+	chainID := chain.Config().ChainID
+	if blockTime, found := p.times[chainID][height]; found {
+		return blockTime, nil
+	}
+	block, err := chain.GetNode().Client.Block(ctx, &height)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to fetch block of %s at height %d: %w", chainID, height, err)
+	}
+	if p.times[chainID] == nil {
+		p.times[chainID] = make(map[int64]time.Time)
+	}
+	p.times[chainID][height] = block.Block.Time
+	return block.Block.Time, nil
+}
+
+// packetsOf returns the packets of the events of the type.
+func packetsOf(events []abci.Event, eventType string) []PacketID {
+	var packets []PacketID
+	for _, attrs := range packetAttributes(events, eventType, nil) {
+		packet, ok := packetOf(attrs)
+		if ok {
+			packets = append(packets, packet)
+		}
+	}
+	return packets
+}
+
+// packetAttributes returns the attributes of the events of the type, of all packets or only the given one.
+func packetAttributes(events []abci.Event, eventType string, only *PacketID) []map[string]string {
+	var matched []map[string]string
+	for _, event := range events {
+		if event.Type != eventType {
+			continue
+		}
+		attrs := make(map[string]string, len(event.Attributes))
+		for _, attr := range event.Attributes {
+			attrs[string(attr.Key)] = string(attr.Value)
+		}
+		if only != nil {
+			packet, ok := packetOf(attrs)
+			if !ok || packet != *only {
+				continue
+			}
+		}
+		matched = append(matched, attrs)
+	}
+	return matched
+}
+
+func packetOf(attrs map[string]string) (PacketID, bool) {
+	sequence, err := strconv.ParseUint(attrs["packet_sequence"], 10, 64)
+	if err != nil {
+		return PacketID{}, false
+	}
+	return PacketID{
+		SrcPort:    attrs["packet_src_port"],
+		SrcChannel: attrs["packet_src_channel"],
+		DstPort:    attrs["packet_dst_port"],
+		DstChannel: attrs["packet_dst_channel"],
+		Sequence:   sequence,
+	}, true
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/docker/docker/client"
+	"go.uber.org/zap"
+)
+
+const (
+	RelayerRly    = "rly"
+	RelayerHermes = "hermes"
+)
+
+var (
+	// HermesImage is built locally from hermes.Dockerfile, see make docker-build-hermes
+	HermesImage   = "hermes"
+	HermesVersion = "local"
+
+	hermesImage = ibc.DockerImage{
+		Repository: HermesImage,
+		Version:    getEnv("HERMES_CI", HermesVersion),
+		UidGid:     hermesUidGid,
+	}
+
+	relayerImpl = flag.String("relayer", getEnv("E2E_RELAYER", RelayerRly), "relayer the tests run with, rly or hermes")
+)
+
+// NewRelayerFactory returns the factory of the relayer selected with the -relayer flag. The options are those of
+// rly and are ignored for Hermes, which always runs the local Hermes image.
+func NewRelayerFactory(log *zap.Logger, options ...relayer.RelayerOption) test.RelayerFactory {
+	switch *relayerImpl {
+	case RelayerHermes:
+		return hermesRelayerFactory{log: log}
+	case RelayerRly:
+		return test.NewBuiltinRelayerFactory(ibc.CosmosRly, log, options...)
+	default:
+		panic(fmt.Sprintf("unknown relayer %q, expected %s or %s", *relayerImpl, RelayerRly, RelayerHermes))
+	}
+}
+
+type hermesRelayerFactory struct {
+	log *zap.Logger
+}
+
+var _ test.RelayerFactory = hermesRelayerFactory{}
+
+func (f hermesRelayerFactory) Build(t test.TestName, cli *client.Client, relayerName, networkID string) ibc.Relayer {
+	r, err := NewHermesRelayer(f.log, t.Name(), cli, relayerName, networkID, hermesImage)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func (f hermesRelayerFactory) Name() string {
+	return RelayerHermes + "@" + hermesImage.Version
+}
+
+func getEnv(key, fallback string) string {
+	if value, found := os.LookupEnv(key); found {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, found := os.LookupEnv(key)
+	if !found {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		panic(fmt.Sprintf("%s=%q is not an integer", key, value))
+	}
+	return n
+}
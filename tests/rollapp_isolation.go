@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+)
+
+// RollappKind is the rollapp binary a rollapp runs.
+type RollappKind string
+
+const (
+	RollappEVM  RollappKind = "evm"
+	RollappWasm RollappKind = "wasm"
+)
+
+var (
+	isolationRollapps = flag.Int("isolation-rollapps", getEnvInt("E2E_ISOLATION_ROLLAPPS", 4),
+		"number of rollapps of the rollapp isolation scenario, at least 2")
+	isolationKinds = flag.String("isolation-kinds", getEnv("E2E_ISOLATION_KINDS", "evm,wasm"),
+		"comma separated rollapp kinds the rollapp isolation scenario cycles through")
+)
+
+// IsolationRollapps returns the kinds of the rollapps of the isolation scenario, set with the -isolation-rollapps
+// and -isolation-kinds flags, and whether each is frozen. The kinds are cycled through, and the rollapps are frozen
+// in a pattern that freezes and spares each kind as soon as there are enough rollapps: the first rollapp is frozen
+// and the second one spared, so at least one rollapp keeps running next to a frozen one.
+func IsolationRollapps() ([]RollappKind, []bool, error) {
+	n := *isolationRollapps
+	if n < 2 {
+		return nil, nil, fmt.Errorf("the rollapp isolation scenario needs at least 2 rollapps, got %d", n)
+	}
+	var cycle []RollappKind
+	for _, kind := range strings.Split(*isolationKinds, ",") {
+		switch kind := RollappKind(strings.TrimSpace(kind)); kind {
+		case RollappEVM, RollappWasm:
+			cycle = append(cycle, kind)
+		default:
+			return nil, nil, fmt.Errorf("unknown rollapp kind %q, expected %s or %s", kind, RollappEVM, RollappWasm)
+		}
+	}
+
+	kinds := make([]RollappKind, n)
+	frozen := make([]bool, n)
+	for i := range kinds {
+		kinds[i] = cycle[i%len(cycle)]
+		frozen[i] = i%4 == 0 || i%4 == 3
+	}
+	return kinds, frozen, nil
+}
+
+// rollappChainSpec returns the chain spec of the i-th rollapp of a test settling on the hub of the test. Every
+// rollapp of a test gets its own chain id.
+func rollappChainSpec(t *testing.T, kind RollappKind, i int) *test.ChainSpec {
+	chainID := fmt.Sprintf("rollapp%s_%d-1", kind, 1234+i)
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = chainID
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+
+	numRollAppFn := 0
+	numRollAppVals := 1
+	spec := &test.ChainSpec{
+		Name: fmt.Sprintf("rollapp%d", i+1),
+		ChainConfig: ibc.ChainConfig{
+			Type:                "rollapp-dym",
+			Name:                fmt.Sprintf("rollapp-temp%d", i),
+			ChainID:             chainID,
+			Bin:                 "rollappd",
+			Denom:               "urax",
+			GasPrices:           "0.0urax",
+			GasAdjustment:       1.1,
+			TrustingPeriod:      "112h",
+			EncodingConfig:      encodingConfig(),
+			NoHostMount:         false,
+			ConfigFileOverrides: configFileOverrides,
+		},
+		NumValidators: &numRollAppVals,
+		NumFullNodes:  &numRollAppFn,
+	}
+
+	switch kind {
+	case RollappEVM:
+		spec.Images = []ibc.DockerImage{rollappEVMImage}
+		spec.Bech32Prefix = "ethm"
+		spec.CoinType = "60"
+		spec.ModifyGenesis = modifyRollappEVMGenesis(rollappEVMGenesisKV)
+	case RollappWasm:
+		spec.Images = []ibc.DockerImage{rollappWasmImage}
+		spec.Bech32Prefix = "rol"
+		spec.CoinType = "118"
+	default:
+		t.Fatalf("unknown rollapp kind %q", kind)
+	}
+	return spec
+}
+
+// RollappProgress is how far a rollapp got on the hub over a scenario.
+type RollappProgress struct {
+	RollappID string
+	Kind      RollappKind
+	Frozen    bool
+	// StartIndex and EndIndex are the latest state indexes at the start and at the end of the scenario
+	StartIndex uint64
+	EndIndex   uint64
+	// FinalizedIndex is the latest finalized state index at the end of the scenario, 0 if none is finalized
+	FinalizedIndex uint64
+}
+
+// QueryRollappProgress completes the progress of the rollapp with its current latest and finalized state indexes.
+func QueryRollappProgress(ctx context.Context, hub *dym_hub.DymHub, progress RollappProgress) (RollappProgress, error) {
+	watcher := NewStateWatcher(hub, progress.RollappID)
+	index, err := watcher.LatestIndex(ctx)
+	if err != nil {
+		return progress, err
+	}
+	progress.EndIndex = index
+
+	// the hub errors until a state update is finalized
+	if finalized, err := watcher.LatestFinalized(ctx); err == nil {
+		progress.FinalizedIndex = finalized.Index
+	}
+	return progress, nil
+}
+
+// LogRollappProgress logs the progress of the rollapps as a table.
+func LogRollappProgress(t *testing.T, rows []RollappProgress) {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROLLAPP\tKIND\tFROZEN\tSTART INDEX\tEND INDEX\tFINALIZED INDEX")
+	for _, row := range rows {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%d\t%d\t%d\n", row.RollappID, row.Kind, row.Frozen, row.StartIndex, row.EndIndex, row.FinalizedIndex)
+	}
+	w.Flush()
+	t.Logf("rollapp state index progress:\n%s", b.String())
+}
@@ -0,0 +1,347 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	"github.com/cosmos/cosmos-sdk/x/params/client/utils"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// TestRollappIsolation generalizes TestOtherRollappNotAffected to any number of rollapps of mixed types.
+// A subset of the rollapps is frozen with fraud proposals, and every other rollapp must keep submitting batches,
+// finalizing them and relaying IBC transfers with the hub.
+func TestRollappIsolation(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	// the rollapps of the scenario and whether each is frozen
+	kinds, frozen, err := IsolationRollapps()
+	require.NoError(t, err)
+
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	var specs []*test.ChainSpec
+	for i, kind := range kinds {
+		specs = append(specs, rollappChainSpec(t, kind, i))
+	}
+	specs = append(specs, &test.ChainSpec{
+		Name: "dymension-hub",
+		ChainConfig: ibc.ChainConfig{
+			Type:                "hub-dym",
+			Name:                "dymension",
+			ChainID:             "dymension_100-1",
+			Images:              []ibc.DockerImage{dymensionImage},
+			Bin:                 "dymd",
+			Bech32Prefix:        "dym",
+			Denom:               "adym",
+			CoinType:            "118",
+			GasPrices:           "0.0adym",
+			EncodingConfig:      encodingConfig(),
+			GasAdjustment:       1.1,
+			TrustingPeriod:      "112h",
+			NoHostMount:         false,
+			ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+			ConfigFileOverrides: nil,
+		},
+		NumValidators: &numHubVals,
+		NumFullNodes:  &numHubFullNodes,
+		ExtraFlags:    extraFlags,
+	})
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), specs)
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapps := make([]*dym_rollapp.DymRollApp, len(kinds))
+	rollappChains := make([]ibc.Chain, len(kinds))
+	rollappIDs := make([]string, len(kinds))
+	for i := range kinds {
+		rollapps[i] = chains[i].(*dym_rollapp.DymRollApp)
+		rollappChains[i] = rollapps[i]
+		rollappIDs[i] = rollapps[i].GetChainID()
+	}
+	dymension := chains[len(kinds)].(*dym_hub.DymHub)
+
+	// Relayer Factory, one relayer and path per rollapp
+	client, network := test.DockerSetup(t)
+
+	ic := test.NewSetup().AddRollUp(dymension, rollappChains...)
+	relayers := make([]ibc.Relayer, len(kinds))
+	paths := make([]string, len(kinds))
+	for i := range kinds {
+		name := fmt.Sprintf("relayer%d", i+1)
+		relayers[i] = NewRelayerFactory(zaptest.NewLogger(t),
+			relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+		).Build(t, client, name, network)
+		paths[i] = fmt.Sprintf("ibc-path-%d", i+1)
+
+		ic = ic.AddRelayer(relayers[i], name).
+			AddLink(test.InterchainLink{
+				Chain1:  dymension,
+				Chain2:  rollapps[i],
+				Relayer: relayers[i],
+				Path:    paths[i],
+			})
+	}
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollappIDs...)
+
+	heighters := []testutil.ChainHeighter{dymension}
+	for _, rollapp := range rollapps {
+		heighters = append(heighters, rollapp)
+	}
+	err = testutil.WaitForBlocks(ctx, 10, heighters...)
+	require.NoError(t, err)
+
+	for i := range kinds {
+		err = relayers[i].StartRelayer(ctx, eRep, paths[i])
+		require.NoError(t, err)
+	}
+
+	t.Cleanup(
+		func() {
+			for _, r := range relayers {
+				err := r.StopRelayer(ctx, eRep)
+				if err != nil {
+					t.Logf("an error occurred while stopping the relayer: %s", err)
+				}
+			}
+		},
+	)
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create a user on the hub and one on every rollapp
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, append([]ibc.Chain{dymension}, rollappChains...)...)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension)
+	require.NoError(t, err)
+
+	dymensionUser := users[0]
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddrs := make([]string, len(kinds))
+	for i, rollapp := range rollapps {
+		rollappUserAddrs[i] = users[1+i].FormattedAddress()
+		testutil.AssertBalance(t, ctx, rollapp, rollappUserAddrs[i], rollapp.Config().Denom, walletAmount)
+	}
+
+	// IBC channel of every rollapp, its counterparty is the channel of the hub
+	channels := make([]ibc.ChannelOutput, len(kinds))
+	for i, rollapp := range rollapps {
+		chans, err := relayers[i].GetChannels(ctx, eRep, rollapp.GetChainID())
+		require.NoError(t, err)
+		require.Len(t, chans, 1)
+		require.NotEmpty(t, chans[0].Counterparty.ChannelID)
+		channels[i] = chans[0]
+	}
+
+	// the fraud proposals freeze the light clients of the rollapps, which the hub only knows once their genesis
+	// events bound them to their channels
+	sequencerAddrs := make([]string, len(kinds))
+	var whitelist []string
+	for i, rollapp := range rollapps {
+		sequencerAddrs[i], err = dymension.AccountKeyBech32WithKeyDir(ctx, "sequencer", rollapp.GetSequencerKeyDir())
+		require.NoError(t, err)
+		if frozen[i] {
+			whitelist = append(whitelist, fmt.Sprintf(`{"address":"%s"}`, sequencerAddrs[i]))
+		}
+	}
+
+	deployerWhitelistParams := json.RawMessage("[" + strings.Join(whitelist, ",") + "]")
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add the sequencers of the rollapps to freeze to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	for i, rollapp := range rollapps {
+		if !frozen[i] {
+			continue
+		}
+		err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp.GetChainID(), channels[i].Counterparty.ChannelID, rollapp.GetSequencerKeyDir())
+		require.NoError(t, err)
+	}
+
+	dymClients, err := relayers[0].GetClients(ctx, eRep, dymension.GetChainID())
+	require.NoError(t, err)
+	require.Len(t, dymClients, len(kinds))
+	clientIDs := make(map[string]string, len(dymClients))
+	for _, client := range dymClients {
+		clientIDs[client.ClientState.ChainID] = client.ClientID
+	}
+
+	progress := make([]RollappProgress, len(kinds))
+	for i, rollapp := range rollapps {
+		startIndex, err := NewStateWatcher(dymension, rollapp.GetChainID()).LatestIndex(ctx)
+		require.NoError(t, err)
+		progress[i] = RollappProgress{RollappID: rollapp.GetChainID(), Kind: kinds[i], Frozen: frozen[i], StartIndex: startIndex}
+	}
+
+	// Submit a fraud proposal against a pending batch of every rollapp to freeze
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+	var fraudProposalIDs []string
+	for i, rollapp := range rollapps {
+		if !frozen[i] {
+			continue
+		}
+		rollappHeight, err := rollapp.Height(ctx)
+		require.NoError(t, err)
+		batchCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+		fraudBatch, err := NewStateWatcher(dymension, rollapp.GetChainID()).WaitForBatchCovering(batchCtx, rollappHeight)
+		cancel()
+		require.NoError(t, err)
+		require.Equal(t, stateStatusPending, fraudBatch.Status)
+
+		clientID, ok := clientIDs[rollapp.GetChainID()]
+		require.True(t, ok, "no client of %s on the hub", rollapp.GetChainID())
+
+		err = dymension.SubmitFraudProposal(
+			ctx, dymensionUser.KeyName(),
+			rollapp.GetChainID(),
+			fmt.Sprint(rollappHeight),
+			sequencerAddrs[i],
+			clientID,
+			"fraud",
+			"fraud",
+			"500000000000"+dymension.Config().Denom,
+		)
+		require.NoError(t, err)
+		// the deployer whitelist proposal is the first one
+		fraudProposalIDs = append(fraudProposalIDs, fmt.Sprint(2+len(fraudProposalIDs)))
+	}
+
+	for _, proposalID := range fraudProposalIDs {
+		err = dymension.VoteOnProposalAllValidators(ctx, proposalID, cosmos.ProposalVoteYes)
+		require.NoError(t, err, "failed to submit votes")
+	}
+	for _, proposalID := range fraudProposalIDs {
+		_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+60, proposalID, cosmos.ProposalStatusPassed)
+		require.NoError(t, err, "fraud proposal %s status did not change to passed", proposalID)
+	}
+
+	// only the rollapps the proposals were about are frozen
+	frozenIndexes := make([]uint64, len(kinds))
+	for i, rollapp := range rollapps {
+		rollappParams, err := dymension.QueryRollappParams(ctx, rollapp.GetChainID())
+		require.NoError(t, err)
+		require.Equal(t, frozen[i], rollappParams.Rollapp.Frozen, "unexpected frozen status of %s", rollapp.GetChainID())
+
+		frozenIndexes[i], err = NewStateWatcher(dymension, rollapp.GetChainID()).LatestIndex(ctx)
+		require.NoError(t, err)
+	}
+
+	// IBC transfers in both directions, only the ones of the rollapps still running go through
+	transferAmount := math.NewInt(1_000_000)
+	sendHeights := make([]uint64, len(kinds))
+	for i, rollapp := range rollapps {
+		transferData := ibc.WalletData{
+			Address: rollappUserAddrs[i],
+			Denom:   dymension.Config().Denom,
+			Amount:  transferAmount,
+		}
+		_, err = dymension.SendIBCTransfer(ctx, channels[i].Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+		if frozen[i] {
+			require.Error(t, err, "transfer to frozen rollapp %s went through", rollapp.GetChainID())
+		} else {
+			require.NoError(t, err)
+		}
+
+		transferData = ibc.WalletData{
+			Address: dymensionUserAddr,
+			Denom:   rollapp.Config().Denom,
+			Amount:  transferAmount,
+		}
+		_, err = rollapp.SendIBCTransfer(ctx, channels[i].ChannelID, rollappUserAddrs[i], transferData, ibc.TransferOptions{})
+		require.NoError(t, err)
+		sendHeights[i], err = rollapp.Height(ctx)
+		require.NoError(t, err)
+	}
+
+	// the batches of the running rollapps keep being submitted and finalized
+	for i, rollapp := range rollapps {
+		if frozen[i] {
+			continue
+		}
+		finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+		_, err = NewStateWatcher(dymension, rollapp.GetChainID()).WaitForHeightFinalized(finalizeCtx, sendHeights[i])
+		cancel()
+		require.NoError(t, err, "rollapp %s did not finalize", rollapp.GetChainID())
+	}
+
+	// give the packets finalized last and the ones a frozen rollapp could still release time to land
+	err = testutil.WaitForBlocks(ctx, 10, dymension)
+	require.NoError(t, err)
+
+	for i := range rollapps {
+		progress[i], err = QueryRollappProgress(ctx, dymension, progress[i])
+		require.NoError(t, err)
+	}
+	LogRollappProgress(t, progress)
+
+	for i, rollapp := range rollapps {
+		rollappIBCDenom := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(channels[i].Counterparty.PortID, channels[i].Counterparty.ChannelID, rollapp.Config().Denom)).IBCDenom()
+		hubIBCDenom := transfertypes.ParseDenomTrace(transfertypes.GetPrefixedDenom(channels[i].PortID, channels[i].ChannelID, dymension.Config().Denom)).IBCDenom()
+
+		if frozen[i] {
+			// a frozen rollapp submits no more batches and none of its transfers is credited
+			require.Equal(t, frozenIndexes[i], progress[i].EndIndex, "frozen rollapp %s still submits batches", rollapp.GetChainID())
+			testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, math.ZeroInt())
+			testutil.AssertBalance(t, ctx, rollapp, rollappUserAddrs[i], hubIBCDenom, math.ZeroInt())
+			continue
+		}
+
+		require.Greater(t, progress[i].EndIndex, frozenIndexes[i], "rollapp %s stopped submitting batches", rollapp.GetChainID())
+		require.Greater(t, progress[i].FinalizedIndex, progress[i].StartIndex, "rollapp %s stopped finalizing", rollapp.GetChainID())
+		testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+		testutil.AssertBalance(t, ctx, rollapp, rollappUserAddrs[i], hubIBCDenom, transferAmount)
+	}
+}
@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/stretchr/testify/require"
+)
+
+// Types of the rollapp packets held by the delayed-ack middleware of the hub.
+const (
+	rollappPacketOnRecv = "ON_RECV"
+	rollappPacketOnAck  = "ON_ACK"
+)
+
+// errRollappPacketNotFound is returned when the hub holds no rollapp packet for an IBC packet.
+var errRollappPacketNotFound = errors.New("rollapp packet not found")
+
+// RollappPacket is an IBC packet of a rollapp held by the delayed-ack middleware of the hub until the rollapp
+// height it was proven at is finalized.
+type RollappPacket struct {
+	RollappID string
+	// Type is the IBC callback held for the packet, one of ON_RECV, ON_ACK and ON_TIMEOUT
+	Type   string
+	Status string
+	// ProofHeight is the rollapp height the packet was proven at
+	ProofHeight        uint64
+	Sequence           uint64
+	SourceChannel      string
+	DestinationChannel string
+	Error              string
+}
+
+// Matches reports whether the rollapp packet holds the given IBC callback of the packet. Packets sent in both
+// directions share sequences and often channel ids, so the callback type tells them apart. An empty type
+// matches any callback.
+func (p RollappPacket) Matches(packetType string, packet ibc.Packet) bool {
+	return (packetType == "" || p.Type == packetType) &&
+		p.Sequence == packet.Sequence &&
+		(packet.SourceChannel == "" || p.SourceChannel == packet.SourceChannel) &&
+		(packet.DestChannel == "" || p.DestinationChannel == packet.DestChannel)
+}
+
+func (p RollappPacket) String() string {
+	s := fmt.Sprintf("%s packet %d %s -> %s of %s (proof height %d, status %s)",
+		p.Type, p.Sequence, p.SourceChannel, p.DestinationChannel, p.RollappID, p.ProofHeight, p.Status)
+	if p.Error != "" {
+		s += ": " + p.Error
+	}
+	return s
+}
+
+// rollappPacketResponse decodes the rollapp packets returned by the delayedack CLI, whose proof height field is
+// named differently across hub versions.
+type rollappPacketResponse struct {
+	RollappID string `json:"rollapp_id"`
+	Packet    struct {
+		Sequence           string `json:"sequence"`
+		SourceChannel      string `json:"source_channel"`
+		DestinationChannel string `json:"destination_channel"`
+	} `json:"packet"`
+	Status           string `json:"status"`
+	ProofHeight      string `json:"ProofHeight"`
+	ProofHeightSnake string `json:"proof_height"`
+	Type             string `json:"type"`
+	Error            string `json:"error"`
+}
+
+// QueryRollappPackets lists the packets of the rollapp held by the hub with the given status (PENDING, FINALIZED
+// or REVERTED), or with any status if status is empty.
+func QueryRollappPackets(ctx context.Context, hub *dym_hub.DymHub, rollappID, status string) ([]RollappPacket, error) {
+	return QueryRollappPacketsAtHeight(ctx, hub, rollappID, status, 0)
+}
+
+// QueryRollappPacketsAtHeight is QueryRollappPackets against the hub state at the given hub height.
+// A height of 0 means the latest height.
+func QueryRollappPacketsAtHeight(ctx context.Context, hub *dym_hub.DymHub, rollappID, status string, hubHeight uint64) ([]RollappPacket, error) {
+	args := []string{"delayedack", "packets-by-rollapp", rollappID}
+	if status != "" {
+		args = append(args, strings.ToUpper(status))
+	}
+	if hubHeight != 0 {
+		args = append(args, "--height", fmt.Sprint(hubHeight))
+	}
+	stdout, _, err := hub.GetNode().ExecQuery(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s packets of %s at hub height %d: %w", status, rollappID, hubHeight, err)
+	}
+
+	var res struct {
+		RollappPackets []rollappPacketResponse `json:"rollappPackets"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal packets of %s: %w", rollappID, err)
+	}
+
+	packets := make([]RollappPacket, 0, len(res.RollappPackets))
+	for _, p := range res.RollappPackets {
+		packet, err := p.toRollappPacket()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse packet of %s: %w", rollappID, err)
+		}
+		packets = append(packets, packet)
+	}
+	return packets, nil
+}
+
+func (p rollappPacketResponse) toRollappPacket() (RollappPacket, error) {
+	sequence, err := strconv.ParseUint(p.Packet.Sequence, 10, 64)
+	if err != nil {
+		return RollappPacket{}, fmt.Errorf("invalid sequence %q: %w", p.Packet.Sequence, err)
+	}
+	proofHeight := p.ProofHeight
+	if proofHeight == "" {
+		proofHeight = p.ProofHeightSnake
+	}
+	height, err := strconv.ParseUint(proofHeight, 10, 64)
+	if err != nil {
+		return RollappPacket{}, fmt.Errorf("invalid proof height %q: %w", proofHeight, err)
+	}
+	return RollappPacket{
+		RollappID:          p.RollappID,
+		Type:               p.Type,
+		Status:             p.Status,
+		ProofHeight:        height,
+		Sequence:           sequence,
+		SourceChannel:      p.Packet.SourceChannel,
+		DestinationChannel: p.Packet.DestinationChannel,
+		Error:              p.Error,
+	}, nil
+}
+
+// QueryPendingPackets lists the packets of the rollapp the hub still holds.
+func QueryPendingPackets(ctx context.Context, hub *dym_hub.DymHub, rollappID string) ([]RollappPacket, error) {
+	return QueryRollappPackets(ctx, hub, rollappID, stateStatusPending)
+}
+
+// FindRollappPacket returns the rollapp packet the hub holds for the callback of an IBC packet sent to or from the
+// rollapp, as of the given hub height. A height of 0 means the latest height.
+func FindRollappPacket(ctx context.Context, hub *dym_hub.DymHub, rollappID, packetType string, packet ibc.Packet, hubHeight uint64) (*RollappPacket, error) {
+	packets, err := QueryRollappPacketsAtHeight(ctx, hub, rollappID, "", hubHeight)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range packets {
+		if p.Matches(packetType, packet) {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%s packet %d %s -> %s at hub height %d: %w",
+		packetType, packet.Sequence, packet.SourceChannel, packet.DestChannel, hubHeight, errRollappPacketNotFound)
+}
+
+// WaitForRollappPacket waits until the hub holds a rollapp packet with the given status for the callback of an
+// IBC packet.
+func WaitForRollappPacket(ctx context.Context, hub *dym_hub.DymHub, rollappID, packetType string, packet ibc.Packet, status string) (*RollappPacket, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(stateWatcherPollInterval)
+	defer ticker.Stop()
+
+	var (
+		last    *RollappPacket
+		lastErr error
+	)
+	for {
+		p, err := FindRollappPacket(ctx, hub, rollappID, packetType, packet, 0)
+		if err != nil {
+			lastErr = err
+		} else {
+			last = p
+			if strings.EqualFold(p.Status, status) {
+				return p, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for %s packet %d of %s to be %s, last seen %v: %w (last query error: %v)",
+				packetType, packet.Sequence, rollappID, status, last, ctx.Err(), lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// PredictPacketRelease predicts the hub height the rollapp packet is released at, that is the height its proof
+// height is finalized at.
+func (w *StateWatcher) PredictPacketRelease(ctx context.Context, p RollappPacket) (*FinalizationPrediction, error) {
+	return w.PredictFinalization(ctx, p.ProofHeight)
+}
+
+// RequirePacketHeld asserts that the hub holds the callback of the IBC packet as a pending rollapp packet, and
+// returns it.
+func RequirePacketHeld(t *testing.T, ctx context.Context, hub *dym_hub.DymHub, rollappID, packetType string, packet ibc.Packet) *RollappPacket {
+	held, err := FindRollappPacket(ctx, hub, rollappID, packetType, packet, 0)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, held.Status, "packet is not held: %s", held)
+	return held
+}
+
+// RequirePacketReleasedAtFinalization waits until the proof height of the held packet is finalized, and asserts
+// that the hub released it in the very block that finalized that height. It returns the release hub height.
+func RequirePacketReleasedAtFinalization(t *testing.T, ctx context.Context, hub *dym_hub.DymHub, held RollappPacket) uint64 {
+	watcher := NewStateWatcher(hub, held.RollappID)
+	disputePeriod, err := watcher.DisputePeriod(ctx)
+	require.NoError(t, err)
+
+	finalizeCtx, cancel := context.WithTimeout(ctx, defaultStateWatchTimeout)
+	defer cancel()
+	state, err := watcher.WaitForHeightFinalized(finalizeCtx, held.ProofHeight)
+	require.NoError(t, err)
+	releaseHeight := state.CreationHeight + disputePeriod
+
+	packet := ibc.Packet{Sequence: held.Sequence, SourceChannel: held.SourceChannel, DestChannel: held.DestinationChannel}
+	before, err := FindRollappPacket(ctx, hub, held.RollappID, held.Type, packet, releaseHeight-1)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, before.Status, "packet released before hub height %d: %s", releaseHeight, before)
+	after, err := FindRollappPacket(ctx, hub, held.RollappID, held.Type, packet, releaseHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusFinalized, after.Status, "packet not released at hub height %d: %s", releaseHeight, after)
+	return releaseHeight
+}
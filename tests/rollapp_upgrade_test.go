@@ -0,0 +1,601 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// rollappHaltHeightDelta is how many rollapp blocks after the upgrade proposal the rollapp halts, enough for the
+// proposal to pass
+const rollappHaltHeightDelta = 150
+
+// rollappWasmUpgradeGenesisKV lets an upgrade proposal pass on the wasm rollapp within the test
+var rollappWasmUpgradeGenesisKV = []cosmos.GenesisKV{
+	{
+		Key:   "app_state.gov.voting_params.voting_period",
+		Value: "30s",
+	},
+	{
+		Key:   "app_state.gov.deposit_params.min_deposit",
+		Value: []map[string]string{{"denom": "urax", "amount": "10000000000"}},
+	},
+}
+
+// This test case upgrades the sequencer and the full node of the rollapp to a newer image. The rollapp halts at
+// the upgrade height and is restarted on the new release. Batch submission resumes right after the last height
+// submitted before the upgrade, the state updates on the hub stay contiguous, and the IBC channel and balances
+// from before the upgrade are preserved and keep working
+func TestRollappUpgrade_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	upgrade, ok := GetRollappUpgrade(RollappEVM)
+	if !ok {
+		t.Skipf("set %s and %s to run the rollapp upgrade", upgrade.ToEnv, upgrade.NameEnv)
+	}
+	t.Logf("rollapp upgrade %s", upgrade)
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	// the full node is upgraded together with the sequencer
+	numRollAppFn := 1
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{upgrade.FromImage()},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	transferAmount := math.NewInt(1_000_000)
+
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+
+	// Before the upgrade: transfers in both directions, settled on the hub
+	transferData := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	transferData = ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// Upgrade the rollapp
+	rollappHeight, err = rollapp1.Height(ctx)
+	require.NoError(t, err)
+	haltHeight := rollappHeight + rollappHaltHeightDelta
+
+	err = SubmitUpgrade(ctx, rollapp1.CosmosChain, rollappUser.KeyName(), upgrade, haltHeight)
+	require.NoError(t, err)
+	// the proposal deposit is refunded once the proposal passed
+	rollappBalance, err := rollapp1.GetBalance(ctx, rollappUserAddr, rollapp1.Config().Denom)
+	require.NoError(t, err)
+
+	haltCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	err = WaitForHalt(haltCtx, rollapp1.CosmosChain, haltHeight)
+	require.NoError(t, err)
+
+	// let the sequencer submit what it produced before halting
+	err = testutil.WaitForBlocks(ctx, 10, dymension)
+	require.NoError(t, err)
+	lastIndex, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+	lastBatch, err := stateWatcher.StateInfo(ctx, lastIndex)
+	require.NoError(t, err)
+	require.LessOrEqual(t, lastBatch.EndHeight(), haltHeight, "batch %s goes past the halt height %d", lastBatch, haltHeight)
+
+	err = SwapImage(ctx, rollapp1.CosmosChain, client, upgrade)
+	require.NoError(t, err)
+
+	// Batch submission resumes right after the last height submitted before the upgrade
+	batchCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	firstBatch, err := stateWatcher.WaitForIndex(batchCtx, lastIndex+1)
+	require.NoError(t, err)
+	require.Equal(t, lastBatch.EndHeight()+1, firstBatch.StartHeight, "state updates of %s do not continue from %s", firstBatch, lastBatch)
+
+	_, err = stateWatcher.WaitForNextBatch(batchCtx)
+	require.NoError(t, err)
+	report, err := AnalyzeBatchHistory(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.NoError(t, report.Err())
+
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	require.Greater(t, rollappHeight, haltHeight, "rollapp did not produce blocks after the upgrade")
+
+	// The full node runs the upgraded binary too and keeps following the sequencer past the upgrade
+	fullNode := rollapp1.FullNodes[0]
+	syncCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	err = WaitForNodeHeight(syncCtx, fullNode, rollappHeight)
+	require.NoError(t, err, "full node stuck at the upgrade")
+	fullNodeBalance, err := QueryNodeBalance(ctx, fullNode, rollappUserAddr, rollapp1.Config().Denom)
+	require.NoError(t, err)
+	require.True(t, rollappBalance.Equal(fullNodeBalance), "balance of %s is %s on %s, want %s", rollappUserAddr, fullNodeBalance, fullNode.Name(), rollappBalance)
+	stateReport, err := CompareNodeState(ctx, rollapp1.GetNode(), fullNode, rollappHeight, rollappUserAddr)
+	require.NoError(t, err)
+	require.NoError(t, stateReport.Err())
+
+	// The IBC channel and the balances are preserved
+	rollappChannels, err := r.GetChannels(ctx, eRep, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Len(t, rollappChannels, 1)
+	require.Equal(t, channel.ChannelID, rollappChannels[0].ChannelID)
+	require.Equal(t, channel.Counterparty.ChannelID, rollappChannels[0].Counterparty.ChannelID)
+	require.Equal(t, "STATE_OPEN", rollappChannels[0].State)
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, rollappBalance)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// and transfers keep working in both directions
+	transferData = ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	transferData = ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount.MulRaw(2))
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount.MulRaw(2))
+}
+
+func TestRollappUpgrade_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	upgrade, ok := GetRollappUpgrade(RollappWasm)
+	if !ok {
+		t.Skipf("set %s and %s to run the rollapp upgrade", upgrade.ToEnv, upgrade.NameEnv)
+	}
+	t.Logf("rollapp upgrade %s", upgrade)
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	// the full node is upgraded together with the sequencer
+	numRollAppFn := 1
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{upgrade.FromImage()},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       cosmos.ModifyGenesis(rollappWasmUpgradeGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	transferAmount := math.NewInt(1_000_000)
+
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+
+	// Before the upgrade: transfers in both directions, settled on the hub
+	transferData := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	transferData = ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// Upgrade the rollapp
+	rollappHeight, err = rollapp1.Height(ctx)
+	require.NoError(t, err)
+	haltHeight := rollappHeight + rollappHaltHeightDelta
+
+	err = SubmitUpgrade(ctx, rollapp1.CosmosChain, rollappUser.KeyName(), upgrade, haltHeight)
+	require.NoError(t, err)
+	// the proposal deposit is refunded once the proposal passed
+	rollappBalance, err := rollapp1.GetBalance(ctx, rollappUserAddr, rollapp1.Config().Denom)
+	require.NoError(t, err)
+
+	haltCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	err = WaitForHalt(haltCtx, rollapp1.CosmosChain, haltHeight)
+	require.NoError(t, err)
+
+	// let the sequencer submit what it produced before halting
+	err = testutil.WaitForBlocks(ctx, 10, dymension)
+	require.NoError(t, err)
+	lastIndex, err := stateWatcher.LatestIndex(ctx)
+	require.NoError(t, err)
+	lastBatch, err := stateWatcher.StateInfo(ctx, lastIndex)
+	require.NoError(t, err)
+	require.LessOrEqual(t, lastBatch.EndHeight(), haltHeight, "batch %s goes past the halt height %d", lastBatch, haltHeight)
+
+	err = SwapImage(ctx, rollapp1.CosmosChain, client, upgrade)
+	require.NoError(t, err)
+
+	// Batch submission resumes right after the last height submitted before the upgrade
+	batchCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	firstBatch, err := stateWatcher.WaitForIndex(batchCtx, lastIndex+1)
+	require.NoError(t, err)
+	require.Equal(t, lastBatch.EndHeight()+1, firstBatch.StartHeight, "state updates of %s do not continue from %s", firstBatch, lastBatch)
+
+	_, err = stateWatcher.WaitForNextBatch(batchCtx)
+	require.NoError(t, err)
+	report, err := AnalyzeBatchHistory(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.NoError(t, report.Err())
+
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	require.Greater(t, rollappHeight, haltHeight, "rollapp did not produce blocks after the upgrade")
+
+	// The full node runs the upgraded binary too and keeps following the sequencer past the upgrade
+	fullNode := rollapp1.FullNodes[0]
+	syncCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	err = WaitForNodeHeight(syncCtx, fullNode, rollappHeight)
+	require.NoError(t, err, "full node stuck at the upgrade")
+	fullNodeBalance, err := QueryNodeBalance(ctx, fullNode, rollappUserAddr, rollapp1.Config().Denom)
+	require.NoError(t, err)
+	require.True(t, rollappBalance.Equal(fullNodeBalance), "balance of %s is %s on %s, want %s", rollappUserAddr, fullNodeBalance, fullNode.Name(), rollappBalance)
+	stateReport, err := CompareNodeState(ctx, rollapp1.GetNode(), fullNode, rollappHeight, rollappUserAddr)
+	require.NoError(t, err)
+	require.NoError(t, stateReport.Err())
+
+	// The IBC channel and the balances are preserved
+	rollappChannels, err := r.GetChannels(ctx, eRep, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Len(t, rollappChannels, 1)
+	require.Equal(t, channel.ChannelID, rollappChannels[0].ChannelID)
+	require.Equal(t, channel.Counterparty.ChannelID, rollappChannels[0].Counterparty.ChannelID)
+	require.Equal(t, "STATE_OPEN", rollappChannels[0].State)
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, rollapp1.Config().Denom, rollappBalance)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// and transfers keep working in both directions
+	transferData = ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	transferData = ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	finalizeCtx, cancel = context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount.MulRaw(2))
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount.MulRaw(2))
+}
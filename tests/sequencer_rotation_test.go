@@ -0,0 +1,1115 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/cosmos/cosmos-sdk/x/params/client/utils"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// This test case registers a second sequencer for the rollapp on its full node and unbonds the genesis sequencer.
+// The hub makes the second sequencer the proposer and the rollapp keeps submitting state updates from it, right
+// after the last height the first one submitted. IBC transfers keep working, and a fraud proposal against a state
+// update of the second sequencer slashes it and leaves the first one alone
+func TestSequencerRotation_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	// the full node runs the second sequencer
+	numRollAppFn := 1
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+			ExtraFlags:    extraFlags,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	// Register the full node as a second sequencer, the genesis sequencer stays the proposer
+	genesisSequencer, err := GenesisSequencer(ctx, dymension, rollapp1)
+	require.NoError(t, err)
+	bond := sdk.NewCoin(dymension.Config().Denom, math.NewInt(1_000_000_000))
+	nextSequencer, err := RegisterSequencer(ctx, dymension, rollapp1, rollapp1.FullNodes[0], bond)
+	require.NoError(t, err)
+
+	sequencers, err := QuerySequencers(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Len(t, sequencers, 2)
+	proposer, err := QueryProposer(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Equal(t, genesisSequencer.Address, proposer.Address)
+
+	next, err := QuerySequencer(ctx, dymension, nextSequencer.Address)
+	require.NoError(t, err)
+	require.Equal(t, sequencerStatusBonded, next.Status)
+	require.False(t, next.Proposer)
+	require.Equal(t, sdk.NewCoins(bond), next.Tokens)
+
+	genesisBefore, err := QuerySequencer(ctx, dymension, genesisSequencer.Address)
+	require.NoError(t, err)
+
+	// Unbonding the proposer hands the proposer role to the next bonded sequencer
+	err = genesisSequencer.Unbond(ctx, dymension)
+	require.NoError(t, err)
+
+	_, err = WaitForProposer(ctx, dymension, rollapp1.GetChainID(), nextSequencer.Address)
+	require.NoError(t, err)
+
+	genesis, err := QuerySequencer(ctx, dymension, genesisSequencer.Address)
+	require.NoError(t, err)
+	require.Equal(t, sequencerStatusUnbonding, genesis.Status)
+	require.False(t, genesis.Proposer)
+	require.False(t, genesis.Jailed)
+
+	// dymint picks its role up from the hub when it starts
+	err = RestartNode(ctx, nextSequencer.Node)
+	require.NoError(t, err)
+	err = RestartNode(ctx, genesisSequencer.Node)
+	require.NoError(t, err)
+
+	// State updates continue from the next sequencer, right after the last height of the genesis sequencer
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	batchCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	firstBatch, err := stateWatcher.WaitForBatchFrom(batchCtx, nextSequencer.Address)
+	require.NoError(t, err)
+
+	lastBatch, err := stateWatcher.StateInfo(ctx, firstBatch.Index-1)
+	require.NoError(t, err)
+	require.Equal(t, genesisSequencer.Address, lastBatch.Sequencer)
+	require.Equal(t, lastBatch.EndHeight()+1, firstBatch.StartHeight, "state updates of %s do not continue from %s", firstBatch, lastBatch)
+
+	_, err = stateWatcher.WaitForNextBatch(batchCtx)
+	require.NoError(t, err)
+	require.Equal(t, nextSequencer.Address, stateWatcher.LastObserved().Sequencer)
+
+	// IBC keeps working in both directions
+	transferAmount := math.NewInt(1_000_000)
+
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+
+	transferData := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	transferData = ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// Fraud: only the sequencer that submitted the fraudulent state update is slashed
+	deployerWhitelistParams := json.RawMessage(fmt.Sprintf(`[{"address":"%s"}]`, genesisSequencer.Address))
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add current dymensionUserAddr to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, channel.Counterparty.ChannelID, genesisSequencer.KeyDir)
+	require.NoError(t, err)
+
+	dymClients, err := r.GetClients(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymClients), 1)
+
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	batchCtx, cancel = context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	fraudBatch, err := stateWatcher.WaitForBatchCovering(batchCtx, rollappHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, fraudBatch.Status)
+	require.Equal(t, nextSequencer.Address, fraudBatch.Sequencer)
+
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	err = dymension.SubmitFraudProposal(
+		ctx, dymensionUser.KeyName(),
+		rollapp1.Config().ChainID,
+		fmt.Sprint(rollappHeight),
+		nextSequencer.Address,
+		dymClients[0].ClientID,
+		"fraud",
+		"fraud",
+		"500000000000"+dymension.Config().Denom,
+	)
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, "2", cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+30, "2", cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "fraud proposal status did not change to passed")
+
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.True(t, rollappParams.Rollapp.Frozen, "rollapp is not frozen")
+
+	slashed, err := QuerySequencer(ctx, dymension, nextSequencer.Address)
+	require.NoError(t, err)
+	require.True(t, slashed.Jailed, "sequencer %s is not jailed", nextSequencer.Address)
+	require.False(t, slashed.Proposer)
+	require.Equal(t, sequencerStatusUnbonded, slashed.Status)
+	require.True(t, slashed.Tokens.IsZero(), "bond of %s is not slashed: %s", nextSequencer.Address, slashed.Tokens)
+
+	genesis, err = QuerySequencer(ctx, dymension, genesisSequencer.Address)
+	require.NoError(t, err)
+	require.False(t, genesis.Jailed, "sequencer %s is jailed", genesisSequencer.Address)
+	require.Equal(t, genesisBefore.Tokens, genesis.Tokens, "bond of %s is slashed", genesisSequencer.Address)
+}
+
+func TestSequencerRotation_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	// the full node runs the second sequencer
+	numRollAppFn := 1
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+			ExtraFlags:    extraFlags,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	// Register the full node as a second sequencer, the genesis sequencer stays the proposer
+	genesisSequencer, err := GenesisSequencer(ctx, dymension, rollapp1)
+	require.NoError(t, err)
+	bond := sdk.NewCoin(dymension.Config().Denom, math.NewInt(1_000_000_000))
+	nextSequencer, err := RegisterSequencer(ctx, dymension, rollapp1, rollapp1.FullNodes[0], bond)
+	require.NoError(t, err)
+
+	sequencers, err := QuerySequencers(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Len(t, sequencers, 2)
+	proposer, err := QueryProposer(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Equal(t, genesisSequencer.Address, proposer.Address)
+
+	next, err := QuerySequencer(ctx, dymension, nextSequencer.Address)
+	require.NoError(t, err)
+	require.Equal(t, sequencerStatusBonded, next.Status)
+	require.False(t, next.Proposer)
+	require.Equal(t, sdk.NewCoins(bond), next.Tokens)
+
+	genesisBefore, err := QuerySequencer(ctx, dymension, genesisSequencer.Address)
+	require.NoError(t, err)
+
+	// Unbonding the proposer hands the proposer role to the next bonded sequencer
+	err = genesisSequencer.Unbond(ctx, dymension)
+	require.NoError(t, err)
+
+	_, err = WaitForProposer(ctx, dymension, rollapp1.GetChainID(), nextSequencer.Address)
+	require.NoError(t, err)
+
+	genesis, err := QuerySequencer(ctx, dymension, genesisSequencer.Address)
+	require.NoError(t, err)
+	require.Equal(t, sequencerStatusUnbonding, genesis.Status)
+	require.False(t, genesis.Proposer)
+	require.False(t, genesis.Jailed)
+
+	// dymint picks its role up from the hub when it starts
+	err = RestartNode(ctx, nextSequencer.Node)
+	require.NoError(t, err)
+	err = RestartNode(ctx, genesisSequencer.Node)
+	require.NoError(t, err)
+
+	// State updates continue from the next sequencer, right after the last height of the genesis sequencer
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	batchCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	firstBatch, err := stateWatcher.WaitForBatchFrom(batchCtx, nextSequencer.Address)
+	require.NoError(t, err)
+
+	lastBatch, err := stateWatcher.StateInfo(ctx, firstBatch.Index-1)
+	require.NoError(t, err)
+	require.Equal(t, genesisSequencer.Address, lastBatch.Sequencer)
+	require.Equal(t, lastBatch.EndHeight()+1, firstBatch.StartHeight, "state updates of %s do not continue from %s", firstBatch, lastBatch)
+
+	_, err = stateWatcher.WaitForNextBatch(batchCtx)
+	require.NoError(t, err)
+	require.Equal(t, nextSequencer.Address, stateWatcher.LastObserved().Sequencer)
+
+	// IBC keeps working in both directions
+	transferAmount := math.NewInt(1_000_000)
+
+	rollappTokenDenom := transfertypes.GetPrefixedDenom(channel.Counterparty.PortID, channel.Counterparty.ChannelID, rollapp1.Config().Denom)
+	rollappIBCDenom := transfertypes.ParseDenomTrace(rollappTokenDenom).IBCDenom()
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+
+	transferData := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	transferData = ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+	_, err = rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, transferData, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	_, err = stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight)
+	require.NoError(t, err)
+	err = testutil.WaitForBlocks(ctx, 2, dymension)
+	require.NoError(t, err)
+
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+	testutil.AssertBalance(t, ctx, dymension, dymensionUserAddr, rollappIBCDenom, transferAmount)
+
+	// Fraud: only the sequencer that submitted the fraudulent state update is slashed
+	deployerWhitelistParams := json.RawMessage(fmt.Sprintf(`[{"address":"%s"}]`, genesisSequencer.Address))
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add current dymensionUserAddr to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, channel.Counterparty.ChannelID, genesisSequencer.KeyDir)
+	require.NoError(t, err)
+
+	dymClients, err := r.GetClients(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymClients), 1)
+
+	rollappHeight, err = rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	batchCtx, cancel = context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	fraudBatch, err := stateWatcher.WaitForBatchCovering(batchCtx, rollappHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, fraudBatch.Status)
+	require.Equal(t, nextSequencer.Address, fraudBatch.Sequencer)
+
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	err = dymension.SubmitFraudProposal(
+		ctx, dymensionUser.KeyName(),
+		rollapp1.Config().ChainID,
+		fmt.Sprint(rollappHeight),
+		nextSequencer.Address,
+		dymClients[0].ClientID,
+		"fraud",
+		"fraud",
+		"500000000000"+dymension.Config().Denom,
+	)
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, "2", cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+30, "2", cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "fraud proposal status did not change to passed")
+
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.True(t, rollappParams.Rollapp.Frozen, "rollapp is not frozen")
+
+	slashed, err := QuerySequencer(ctx, dymension, nextSequencer.Address)
+	require.NoError(t, err)
+	require.True(t, slashed.Jailed, "sequencer %s is not jailed", nextSequencer.Address)
+	require.False(t, slashed.Proposer)
+	require.Equal(t, sequencerStatusUnbonded, slashed.Status)
+	require.True(t, slashed.Tokens.IsZero(), "bond of %s is not slashed: %s", nextSequencer.Address, slashed.Tokens)
+
+	genesis, err = QuerySequencer(ctx, dymension, genesisSequencer.Address)
+	require.NoError(t, err)
+	require.False(t, genesis.Jailed, "sequencer %s is jailed", genesisSequencer.Address)
+	require.Equal(t, genesisBefore.Tokens, genesis.Tokens, "bond of %s is slashed", genesisSequencer.Address)
+}
+
+// This test case registers a second sequencer for the rollapp and submits a fraud proposal against a state update
+// of the proposer. The hub freezes the rollapp, slashes and jails the proposer and force-unbonds the second
+// sequencer, returning its bond, so the frozen rollapp is left without a proposer
+func TestSequencerJailed_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	// the full node runs the second sequencer
+	numRollAppFn := 1
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+			ExtraFlags:    extraFlags,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	dymensionUser := users[0]
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	// Register the full node as a second sequencer, the genesis sequencer stays the proposer
+	genesisSequencer, err := GenesisSequencer(ctx, dymension, rollapp1)
+	require.NoError(t, err)
+	bond := sdk.NewCoin(dymension.Config().Denom, math.NewInt(1_000_000_000))
+	nextSequencer, err := RegisterSequencer(ctx, dymension, rollapp1, rollapp1.FullNodes[0], bond)
+	require.NoError(t, err)
+
+	proposer, err := QueryProposer(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Equal(t, genesisSequencer.Address, proposer.Address)
+
+	nextBefore, err := QuerySequencer(ctx, dymension, nextSequencer.Address)
+	require.NoError(t, err)
+	require.Equal(t, sequencerStatusBonded, nextBefore.Status)
+	nextBalanceBefore, err := dymension.GetBalance(ctx, nextSequencer.Address, dymension.Config().Denom)
+	require.NoError(t, err)
+
+	deployerWhitelistParams := json.RawMessage(fmt.Sprintf(`[{"address":"%s"}]`, genesisSequencer.Address))
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add current dymensionUserAddr to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, channel.Counterparty.ChannelID, genesisSequencer.KeyDir)
+	require.NoError(t, err)
+
+	dymClients, err := r.GetClients(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymClients), 1)
+
+	// the fraud height has to be in a state update of the proposer that is still pending
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	batchCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	fraudBatch, err := stateWatcher.WaitForBatchCovering(batchCtx, rollappHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, fraudBatch.Status)
+	require.Equal(t, genesisSequencer.Address, fraudBatch.Sequencer)
+
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	err = dymension.SubmitFraudProposal(
+		ctx, dymensionUser.KeyName(),
+		rollapp1.Config().ChainID,
+		fmt.Sprint(rollappHeight),
+		genesisSequencer.Address,
+		dymClients[0].ClientID,
+		"fraud",
+		"fraud",
+		"500000000000"+dymension.Config().Denom,
+	)
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, "2", cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+30, "2", cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "fraud proposal status did not change to passed")
+
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.True(t, rollappParams.Rollapp.Frozen, "rollapp is not frozen")
+
+	// the proposer is jailed and loses its bond
+	jailed, err := QuerySequencer(ctx, dymension, genesisSequencer.Address)
+	require.NoError(t, err)
+	require.True(t, jailed.Jailed, "sequencer %s is not jailed", genesisSequencer.Address)
+	require.False(t, jailed.Proposer)
+	require.Equal(t, sequencerStatusUnbonded, jailed.Status)
+	require.True(t, jailed.Tokens.IsZero(), "bond of %s is not slashed: %s", genesisSequencer.Address, jailed.Tokens)
+
+	// the second sequencer is unbonded without being slashed and gets its bond back
+	next, err := QuerySequencer(ctx, dymension, nextSequencer.Address)
+	require.NoError(t, err)
+	require.False(t, next.Jailed, "sequencer %s is jailed", nextSequencer.Address)
+	require.False(t, next.Proposer)
+	require.Equal(t, sequencerStatusUnbonded, next.Status)
+	require.True(t, next.Tokens.IsZero(), "bond of %s is not returned: %s", nextSequencer.Address, next.Tokens)
+	nextBalance, err := dymension.GetBalance(ctx, nextSequencer.Address, dymension.Config().Denom)
+	require.NoError(t, err)
+	require.Equal(t, nextBalanceBefore.Add(nextBefore.Tokens.AmountOf(dymension.Config().Denom)), nextBalance)
+
+	_, err = QueryProposer(ctx, dymension, rollapp1.GetChainID())
+	require.Error(t, err, "frozen rollapp %s still has a proposer", rollapp1.GetChainID())
+}
+
+func TestSequencerJailed_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	// the full node runs the second sequencer
+	numRollAppFn := 1
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+			ExtraFlags:    extraFlags,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	dymensionUser := users[0]
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	// Register the full node as a second sequencer, the genesis sequencer stays the proposer
+	genesisSequencer, err := GenesisSequencer(ctx, dymension, rollapp1)
+	require.NoError(t, err)
+	bond := sdk.NewCoin(dymension.Config().Denom, math.NewInt(1_000_000_000))
+	nextSequencer, err := RegisterSequencer(ctx, dymension, rollapp1, rollapp1.FullNodes[0], bond)
+	require.NoError(t, err)
+
+	proposer, err := QueryProposer(ctx, dymension, rollapp1.GetChainID())
+	require.NoError(t, err)
+	require.Equal(t, genesisSequencer.Address, proposer.Address)
+
+	nextBefore, err := QuerySequencer(ctx, dymension, nextSequencer.Address)
+	require.NoError(t, err)
+	require.Equal(t, sequencerStatusBonded, nextBefore.Status)
+	nextBalanceBefore, err := dymension.GetBalance(ctx, nextSequencer.Address, dymension.Config().Denom)
+	require.NoError(t, err)
+
+	deployerWhitelistParams := json.RawMessage(fmt.Sprintf(`[{"address":"%s"}]`, genesisSequencer.Address))
+	propTx, err := dymension.ParamChangeProposal(ctx, dymensionUser.KeyName(), &utils.ParamChangeProposalJSON{
+		Title:       "Add new deployer_whitelist",
+		Description: "Add current dymensionUserAddr to the deployer_whitelist",
+		Changes: utils.ParamChangesJSON{
+			utils.NewParamChangeJSON("rollapp", "DeployerWhitelist", deployerWhitelistParams),
+		},
+		Deposit: "500000000000" + dymension.Config().Denom, // greater than min deposit
+	})
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, propTx.ProposalID, cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	height, err := dymension.Height(ctx)
+	require.NoError(t, err, "error fetching height")
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, height, height+20, propTx.ProposalID, cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "proposal status did not change to passed")
+
+	err = dymension.GetNode().TriggerGenesisEvent(ctx, "sequencer", rollapp1.Config().ChainID, channel.Counterparty.ChannelID, genesisSequencer.KeyDir)
+	require.NoError(t, err)
+
+	dymClients, err := r.GetClients(ctx, eRep, dymension.Config().ChainID)
+	require.NoError(t, err)
+	require.Equal(t, len(dymClients), 1)
+
+	// the fraud height has to be in a state update of the proposer that is still pending
+	rollappHeight, err := rollapp1.GetNode().Height(ctx)
+	require.NoError(t, err)
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	batchCtx, cancel := context.WithTimeout(ctx, 3*time.Minute)
+	defer cancel()
+	fraudBatch, err := stateWatcher.WaitForBatchCovering(batchCtx, rollappHeight)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusPending, fraudBatch.Status)
+	require.Equal(t, genesisSequencer.Address, fraudBatch.Sequencer)
+
+	fraudHubHeight, err := dymension.Height(ctx)
+	require.NoError(t, err)
+
+	err = dymension.SubmitFraudProposal(
+		ctx, dymensionUser.KeyName(),
+		rollapp1.Config().ChainID,
+		fmt.Sprint(rollappHeight),
+		genesisSequencer.Address,
+		dymClients[0].ClientID,
+		"fraud",
+		"fraud",
+		"500000000000"+dymension.Config().Denom,
+	)
+	require.NoError(t, err)
+
+	err = dymension.VoteOnProposalAllValidators(ctx, "2", cosmos.ProposalVoteYes)
+	require.NoError(t, err, "failed to submit votes")
+
+	_, err = cosmos.PollForProposalStatus(ctx, dymension.CosmosChain, fraudHubHeight, fraudHubHeight+30, "2", cosmos.ProposalStatusPassed)
+	require.NoError(t, err, "fraud proposal status did not change to passed")
+
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.True(t, rollappParams.Rollapp.Frozen, "rollapp is not frozen")
+
+	// the proposer is jailed and loses its bond
+	jailed, err := QuerySequencer(ctx, dymension, genesisSequencer.Address)
+	require.NoError(t, err)
+	require.True(t, jailed.Jailed, "sequencer %s is not jailed", genesisSequencer.Address)
+	require.False(t, jailed.Proposer)
+	require.Equal(t, sequencerStatusUnbonded, jailed.Status)
+	require.True(t, jailed.Tokens.IsZero(), "bond of %s is not slashed: %s", genesisSequencer.Address, jailed.Tokens)
+
+	// the second sequencer is unbonded without being slashed and gets its bond back
+	next, err := QuerySequencer(ctx, dymension, nextSequencer.Address)
+	require.NoError(t, err)
+	require.False(t, next.Jailed, "sequencer %s is jailed", nextSequencer.Address)
+	require.False(t, next.Proposer)
+	require.Equal(t, sequencerStatusUnbonded, next.Status)
+	require.True(t, next.Tokens.IsZero(), "bond of %s is not returned: %s", nextSequencer.Address, next.Tokens)
+	nextBalance, err := dymension.GetBalance(ctx, nextSequencer.Address, dymension.Config().Denom)
+	require.NoError(t, err)
+	require.Equal(t, nextBalanceBefore.Add(nextBefore.Tokens.AmountOf(dymension.Config().Denom)), nextBalance)
+
+	_, err = QueryProposer(ctx, dymension, rollapp1.GetChainID())
+	require.Error(t, err, "frozen rollapp %s still has a proposer", rollapp1.GetChainID())
+}
@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+)
+
+const (
+	sequencerStatusBonded    = "OPERATING_STATUS_BONDED"
+	sequencerStatusUnbonding = "OPERATING_STATUS_UNBONDING"
+	sequencerStatusUnbonded  = "OPERATING_STATUS_UNBONDED"
+
+	// sequencerKeyName is the name of the hub key of every sequencer, each in the key dir of its own rollapp node
+	sequencerKeyName = "sequencer"
+)
+
+// Sequencer is a sequencer of a rollapp as registered on the hub.
+type Sequencer struct {
+	Address   string
+	RollappID string
+	Jailed    bool
+	// Proposer is set on the one sequencer of the rollapp allowed to submit state updates
+	Proposer bool
+	Status   string
+	Tokens   sdk.Coins
+}
+
+type sequencerResponse struct {
+	SequencerAddress string    `json:"sequencerAddress"`
+	RollappID        string    `json:"rollappId"`
+	Jailed           bool      `json:"jailed"`
+	Proposer         bool      `json:"proposer"`
+	Status           string    `json:"status"`
+	Tokens           sdk.Coins `json:"tokens"`
+}
+
+func (s sequencerResponse) toSequencer() Sequencer {
+	return Sequencer{
+		Address:   s.SequencerAddress,
+		RollappID: s.RollappID,
+		Jailed:    s.Jailed,
+		Proposer:  s.Proposer,
+		Status:    s.Status,
+		Tokens:    s.Tokens,
+	}
+}
+
+// RollappSequencer is a rollapp node registered as a sequencer on the hub, with the dir of the hub key it signs
+// its state updates with.
+type RollappSequencer struct {
+	Node    *cosmos.Node
+	KeyDir  string
+	Address string
+}
+
+// GenesisSequencer returns the sequencer the rollapp was registered with, run by its first validator.
+func GenesisSequencer(ctx context.Context, hub *dym_hub.DymHub, rollapp *dym_rollapp.DymRollApp) (RollappSequencer, error) {
+	keyDir := rollapp.GetSequencerKeyDir()
+	address, err := hub.AccountKeyBech32WithKeyDir(ctx, sequencerKeyName, keyDir)
+	if err != nil {
+		return RollappSequencer{}, fmt.Errorf("failed to get genesis sequencer address of %s: %w", rollapp.GetChainID(), err)
+	}
+	return RollappSequencer{Node: rollapp.Validators[0], KeyDir: keyDir, Address: address}, nil
+}
+
+// RegisterSequencer registers the rollapp node as an additional sequencer of the rollapp, bonding bond from a new
+// hub key funded by the hub faucet. The key is created in the home dir of the node, where dymint looks for it.
+func RegisterSequencer(ctx context.Context, hub *dym_hub.DymHub, rollapp *dym_rollapp.DymRollApp, node *cosmos.Node, bond sdk.Coin) (RollappSequencer, error) {
+	keyDir := node.HomeDir()
+	if err := hub.GetNode().CreateKeyWithKeyDir(ctx, sequencerKeyName, keyDir); err != nil {
+		return RollappSequencer{}, fmt.Errorf("failed to create sequencer key in %s: %w", keyDir, err)
+	}
+	address, err := hub.AccountKeyBech32WithKeyDir(ctx, sequencerKeyName, keyDir)
+	if err != nil {
+		return RollappSequencer{}, fmt.Errorf("failed to get sequencer address: %w", err)
+	}
+
+	// enough for the bond and the fees of the state updates
+	fund := ibc.WalletData{
+		Address: address,
+		Denom:   bond.Denom,
+		Amount:  bond.Amount.MulRaw(10),
+	}
+	if err := hub.SendFunds(ctx, "faucet", fund); err != nil {
+		return RollappSequencer{}, fmt.Errorf("failed to fund sequencer %s: %w", address, err)
+	}
+
+	stdout, _, err := node.ExecBin(ctx, "dymint", "show-sequencer")
+	if err != nil {
+		return RollappSequencer{}, fmt.Errorf("failed to show dymint key of %s: %w", node.Name(), err)
+	}
+	pubKey := string(bytes.TrimSuffix(stdout, []byte("\n")))
+
+	description := fmt.Sprintf(`{"Moniker":"%s","Identity":"","Website":"","SecurityContact":"","Details":""}`, node.Name())
+	_, err = hub.GetNode().ExecTx(ctx, sequencerKeyName,
+		"sequencer", "create-sequencer", pubKey, rollapp.GetChainID(), description, bond.String(),
+		"--broadcast-mode", "block", "--keyring-dir", keyDir+"/sequencer_keys")
+	if err != nil {
+		return RollappSequencer{}, fmt.Errorf("failed to register sequencer %s of %s: %w", address, rollapp.GetChainID(), err)
+	}
+	return RollappSequencer{Node: node, KeyDir: keyDir, Address: address}, nil
+}
+
+// Unbond starts unbonding the sequencer. If it is the proposer the hub rotates to the next bonded sequencer.
+func (s RollappSequencer) Unbond(ctx context.Context, hub *dym_hub.DymHub) error {
+	_, err := hub.GetNode().ExecTx(ctx, sequencerKeyName,
+		"sequencer", "unbond",
+		"--broadcast-mode", "block", "--keyring-dir", s.KeyDir+"/sequencer_keys")
+	if err != nil {
+		return fmt.Errorf("failed to unbond sequencer %s: %w", s.Address, err)
+	}
+	return nil
+}
+
+// QuerySequencers lists the sequencers registered for the rollapp.
+func QuerySequencers(ctx context.Context, hub *dym_hub.DymHub, rollappID string) ([]Sequencer, error) {
+	stdout, _, err := hub.GetNode().ExecQuery(ctx, "sequencer", "show-sequencers-by-rollapp", rollappID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sequencers of %s: %w", rollappID, err)
+	}
+
+	var res struct {
+		Sequencers []sequencerResponse `json:"sequencers"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sequencers of %s: %w", rollappID, err)
+	}
+
+	sequencers := make([]Sequencer, 0, len(res.Sequencers))
+	for _, s := range res.Sequencers {
+		sequencers = append(sequencers, s.toSequencer())
+	}
+	return sequencers, nil
+}
+
+// QuerySequencer returns the sequencer registered with the given address.
+func QuerySequencer(ctx context.Context, hub *dym_hub.DymHub, address string) (*Sequencer, error) {
+	stdout, _, err := hub.GetNode().ExecQuery(ctx, "sequencer", "show-sequencer", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sequencer %s: %w", address, err)
+	}
+
+	var res struct {
+		Sequencer sequencerResponse `json:"sequencer"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sequencer %s: %w", address, err)
+	}
+	sequencer := res.Sequencer.toSequencer()
+	return &sequencer, nil
+}
+
+// QueryProposer returns the proposer of the rollapp.
+func QueryProposer(ctx context.Context, hub *dym_hub.DymHub, rollappID string) (*Sequencer, error) {
+	sequencers, err := QuerySequencers(ctx, hub, rollappID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sequencers {
+		if s.Proposer {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("rollapp %s has no proposer among its %d sequencers", rollappID, len(sequencers))
+}
+
+// WaitForProposer waits until the sequencer with the given address is the proposer of the rollapp.
+func WaitForProposer(ctx context.Context, hub *dym_hub.DymHub, rollappID, address string) (*Sequencer, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(stateWatcherPollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		proposer, err := QueryProposer(ctx, hub, rollappID)
+		if err == nil && proposer.Address == address {
+			return proposer, nil
+		}
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("proposer is %s", proposer.Address)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for %s to be the proposer of %s: %w (last: %v)", address, rollappID, ctx.Err(), lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForBatchFrom waits until the rollapp submits a state update signed by the sequencer with the given address.
+func (w *StateWatcher) WaitForBatchFrom(ctx context.Context, address string) (*StateUpdate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	for {
+		batch, err := w.WaitForNextBatch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("no state update of %s from %s: %w", w.rollappID, address, err)
+		}
+		if batch.Sequencer == address {
+			return batch, nil
+		}
+	}
+}
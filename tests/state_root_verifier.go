@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+)
+
+// hubBlockDescriptor is a block descriptor as returned by the hub CLI. The state root is base64 encoded in the
+// JSON output.
+type hubBlockDescriptor struct {
+	Height    string `json:"height"`
+	StateRoot []byte `json:"stateRoot"`
+}
+
+// BlockMismatch is a difference between a block descriptor on the hub and the block produced by the rollapp.
+type BlockMismatch struct {
+	StateIndex uint64
+	Height     uint64
+	Field      string
+	Hub        string
+	Rollapp    string
+}
+
+func (m BlockMismatch) String() string {
+	return fmt.Sprintf("state index %d, rollapp height %d: %s on hub %s, on rollapp %s",
+		m.StateIndex, m.Height, m.Field, m.Hub, m.Rollapp)
+}
+
+// StateRootReport is the result of comparing the block descriptors on the hub with the rollapp blocks.
+type StateRootReport struct {
+	RollappID string
+	// Verified is the number of block descriptors whose state root was compared
+	Verified   int
+	Mismatches []BlockMismatch
+}
+
+func (r *StateRootReport) String() string {
+	return fmt.Sprintf("rollapp %s: %d state roots verified, %d mismatches", r.RollappID, r.Verified, len(r.Mismatches))
+}
+
+// Err returns an error listing every mismatch found, or nil if the hub reflects the rollapp chain.
+func (r *StateRootReport) Err() error {
+	if len(r.Mismatches) == 0 {
+		return nil
+	}
+	lines := make([]string, len(r.Mismatches))
+	for i, m := range r.Mismatches {
+		lines[i] = m.String()
+	}
+	return fmt.Errorf("%d of %d block descriptors of rollapp %s do not match the rollapp blocks:\n%s",
+		len(r.Mismatches), r.Verified, r.RollappID, strings.Join(lines, "\n"))
+}
+
+// VerifyStateRoots compares every block descriptor the hub holds for the rollapp, from state index fromIndex
+// up to and including toIndex, with the block the rollapp node has at the same height.
+// The state root must equal the app hash of the rollapp block. The pinned hub records no block timestamps, so only
+// state roots are compared. A toIndex of 0 means the latest state index.
+func VerifyStateRoots(ctx context.Context, hub *dym_hub.DymHub, rollapp *cosmos.CosmosChain, fromIndex, toIndex uint64) (*StateRootReport, error) {
+	rollappID := rollapp.Config().ChainID
+	if fromIndex == 0 {
+		fromIndex = 1
+	}
+	if toIndex == 0 {
+		latest, err := NewStateWatcher(hub, rollappID).LatestIndex(ctx)
+		if err != nil {
+			return nil, err
+		}
+		toIndex = latest
+	}
+
+	report := &StateRootReport{RollappID: rollappID}
+	rollappNode := rollapp.GetNode()
+	for index := fromIndex; index <= toIndex; index++ {
+		bds, err := queryHubBlockDescriptors(ctx, hub, rollappID, index)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, bd := range bds {
+			height, err := strconv.ParseInt(bd.Height, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse block descriptor height %q of state index %d: %w", bd.Height, index, err)
+			}
+			block, err := rollappNode.Client.Block(ctx, &height)
+			if err != nil {
+				return nil, fmt.Errorf("failed to query rollapp %s block at height %d: %w", rollappID, height, err)
+			}
+
+			report.Verified++
+			if appHash := block.Block.Header.AppHash; !bytes.Equal(bd.StateRoot, appHash) {
+				report.Mismatches = append(report.Mismatches, BlockMismatch{
+					StateIndex: index,
+					Height:     uint64(height),
+					Field:      "state root",
+					Hub:        hex.EncodeToString(bd.StateRoot),
+					Rollapp:    appHash.String(),
+				})
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func queryHubBlockDescriptors(ctx context.Context, hub *dym_hub.DymHub, rollappID string, index uint64) ([]hubBlockDescriptor, error) {
+	stdout, _, err := hub.GetNode().ExecQuery(ctx, "rollapp", "state", rollappID, "--index", fmt.Sprint(index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state index %d of %s: %w", index, rollappID, err)
+	}
+
+	var res struct {
+		StateInfo struct {
+			BDs struct {
+				BD []hubBlockDescriptor `json:"BD"`
+			} `json:"BDs"`
+		} `json:"stateInfo"`
+	}
+	if err := json.Unmarshal(stdout, &res); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state index %d of %s: %w", index, rollappID, err)
+	}
+	return res.StateInfo.BDs.BD, nil
+}
@@ -0,0 +1,355 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	dymensiontesting "github.com/decentrio/rollup-e2e-testing/dymension"
+)
+
+const (
+	stateStatusPending   = "PENDING"
+	stateStatusFinalized = "FINALIZED"
+	stateStatusReverted  = "REVERTED"
+
+	// stateWatcherPollInterval matches the hub block time; every query execs into the hub container,
+	// so polling faster only adds load.
+	stateWatcherPollInterval = 2 * time.Second
+	// defaultStateWatchTimeout bounds waits whose context has no deadline of its own.
+	defaultStateWatchTimeout = 5 * time.Minute
+)
+
+// StateUpdate is a StateInfo record submitted by a rollapp sequencer to the hub, with its numeric fields parsed.
+type StateUpdate struct {
+	RollappID        string
+	Index            uint64
+	Sequencer        string
+	StartHeight      uint64
+	NumBlocks        uint64
+	CreationHeight   uint64 // hub height
+	Status           string
+	DAPath           string
+	BlockDescriptors []dymensiontesting.BlockDescriptor
+}
+
+// EndHeight returns the last rollapp height included in the batch.
+func (s StateUpdate) EndHeight() uint64 {
+	if s.NumBlocks == 0 {
+		return s.StartHeight
+	}
+	return s.StartHeight + s.NumBlocks - 1
+}
+
+// Covers reports whether the batch includes the given rollapp height.
+func (s StateUpdate) Covers(height uint64) bool {
+	return s.NumBlocks > 0 && height >= s.StartHeight && height <= s.EndHeight()
+}
+
+func (s StateUpdate) String() string {
+	return fmt.Sprintf("index %d (rollapp heights %d-%d, created at hub height %d, status %s, DA path %q)",
+		s.Index, s.StartHeight, s.EndHeight(), s.CreationHeight, s.Status, s.DAPath)
+}
+
+// StateWatchTimeoutError is returned when a StateWatcher wait runs out of time.
+// It carries the last state update observed so the failure shows how far the rollapp got.
+type StateWatchTimeoutError struct {
+	RollappID    string
+	WaitingFor   string
+	LastObserved *StateUpdate
+	Err          error
+}
+
+func (e *StateWatchTimeoutError) Error() string {
+	last := "no state update observed"
+	if e.LastObserved != nil {
+		last = "last observed state update: " + e.LastObserved.String()
+	}
+	return fmt.Sprintf("timed out waiting for %s of rollapp %s, %s: %v", e.WaitingFor, e.RollappID, last, e.Err)
+}
+
+func (e *StateWatchTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// StateWatcher follows the state updates a rollapp submits to the hub.
+// Every wait is bounded by the deadline of the context it is given, or by defaultStateWatchTimeout if there is none.
+type StateWatcher struct {
+	hub       *dym_hub.DymHub
+	rollappID string
+
+	mu   sync.Mutex
+	last *StateUpdate
+}
+
+func NewStateWatcher(hub *dym_hub.DymHub, rollappID string) *StateWatcher {
+	return &StateWatcher{
+		hub:       hub,
+		rollappID: rollappID,
+	}
+}
+
+// LastObserved returns the most recent state update seen by the watcher, or nil if none was seen yet.
+func (w *StateWatcher) LastObserved() *StateUpdate {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.last
+}
+
+func (w *StateWatcher) observe(update *StateUpdate) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.last == nil || update.Index > w.last.Index || (update.Index == w.last.Index && update.Status != w.last.Status) {
+		w.last = update
+	}
+}
+
+// LatestIndex returns the latest state index of the rollapp on the hub.
+func (w *StateWatcher) LatestIndex(ctx context.Context) (uint64, error) {
+	res, err := w.hub.GetNode().QueryLatestStateIndex(ctx, w.rollappID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query latest state index of %s: %w", w.rollappID, err)
+	}
+	index, err := strconv.ParseUint(res.StateIndex.Index, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse latest state index %q: %w", res.StateIndex.Index, err)
+	}
+	return index, nil
+}
+
+// StateInfo returns the state update stored on the hub at the given index.
+func (w *StateWatcher) StateInfo(ctx context.Context, index uint64) (*StateUpdate, error) {
+	// the hub treats index 0 as "latest", state indexes start at 1
+	if index == 0 {
+		return nil, fmt.Errorf("invalid state index 0 for rollapp %s", w.rollappID)
+	}
+	return w.queryState(ctx, "--index", fmt.Sprint(index))
+}
+
+// StateInfoAtHeight returns the state update that includes the given rollapp height.
+func (w *StateWatcher) StateInfoAtHeight(ctx context.Context, rollappHeight uint64) (*StateUpdate, error) {
+	return w.queryState(ctx, "--rollapp-height", fmt.Sprint(rollappHeight))
+}
+
+func (w *StateWatcher) queryState(ctx context.Context, flags ...string) (*StateUpdate, error) {
+	update, err := w.fetchState(ctx, flags...)
+	if err != nil {
+		return nil, err
+	}
+	w.observe(update)
+	return update, nil
+}
+
+// fetchState queries a state update without recording it as observed, for queries of past hub heights.
+func (w *StateWatcher) fetchState(ctx context.Context, flags ...string) (*StateUpdate, error) {
+	command := append([]string{"rollapp", "state", w.rollappID}, flags...)
+	stdout, _, err := w.hub.GetNode().ExecQuery(ctx, command...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query state of %s %v: %w", w.rollappID, flags, err)
+	}
+
+	var state dymensiontesting.RollappState
+	if err := json.Unmarshal(stdout, &state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal state of %s: %w", w.rollappID, err)
+	}
+
+	return parseStateInfo(state.StateInfo)
+}
+
+func parseStateInfo(info dymensiontesting.StateInfo) (*StateUpdate, error) {
+	index, err := strconv.ParseUint(info.StateInfoIndex.Index, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state info index %q: %w", info.StateInfoIndex.Index, err)
+	}
+	startHeight, err := strconv.ParseUint(info.StartHeight, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state info start height %q: %w", info.StartHeight, err)
+	}
+	numBlocks, err := strconv.ParseUint(info.NumBlocks, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state info num blocks %q: %w", info.NumBlocks, err)
+	}
+	creationHeight, err := strconv.ParseUint(info.CreationHeight, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse state info creation height %q: %w", info.CreationHeight, err)
+	}
+
+	return &StateUpdate{
+		RollappID:        info.StateInfoIndex.RollappId,
+		Index:            index,
+		Sequencer:        info.Sequencer,
+		StartHeight:      startHeight,
+		NumBlocks:        numBlocks,
+		CreationHeight:   creationHeight,
+		Status:           info.Status,
+		DAPath:           info.DAPath,
+		BlockDescriptors: info.BlockDescriptors.BD,
+	}, nil
+}
+
+// Watch streams every state update of the rollapp starting at fromIndex, in index order, as soon as it lands on the hub.
+// The update channel is closed when ctx is done; the error channel then yields a *StateWatchTimeoutError.
+// Query failures are retried until ctx is done, so a restarting hub node does not end the stream.
+func (w *StateWatcher) Watch(ctx context.Context, fromIndex uint64) (<-chan StateUpdate, <-chan error) {
+	updates := make(chan StateUpdate)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(updates)
+
+		ticker := time.NewTicker(stateWatcherPollInterval)
+		defer ticker.Stop()
+
+		next := fromIndex
+		if next == 0 {
+			next = 1
+		}
+		var lastErr error
+		for {
+			latest, err := w.LatestIndex(ctx)
+			if err != nil {
+				lastErr = err
+			}
+			for ; err == nil && next <= latest; next++ {
+				var update *StateUpdate
+				update, err = w.StateInfo(ctx, next)
+				if err != nil {
+					lastErr = err
+					break
+				}
+				select {
+				case updates <- *update:
+				case <-ctx.Done():
+					errc <- w.timeoutError(ctx, fmt.Sprintf("state updates from index %d", fromIndex), lastErr)
+					return
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				errc <- w.timeoutError(ctx, fmt.Sprintf("state updates from index %d", fromIndex), lastErr)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return updates, errc
+}
+
+// WaitForIndex waits until the state update at index is on the hub and returns it.
+func (w *StateWatcher) WaitForIndex(ctx context.Context, index uint64) (*StateUpdate, error) {
+	return w.poll(ctx, fmt.Sprintf("state index %d", index), func(ctx context.Context) (*StateUpdate, bool, error) {
+		latest, err := w.LatestIndex(ctx)
+		if err != nil || latest < index {
+			return nil, false, err
+		}
+		update, err := w.StateInfo(ctx, index)
+		return update, err == nil, err
+	})
+}
+
+// WaitForNextBatch waits for the first state update submitted after the call and returns it.
+func (w *StateWatcher) WaitForNextBatch(ctx context.Context) (*StateUpdate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	// the hub may not answer yet right after the chains start, so the latest index is polled like the rest
+	var next uint64
+	_, err := w.poll(ctx, "the latest state index", func(ctx context.Context) (*StateUpdate, bool, error) {
+		latest, err := w.LatestIndex(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		next = latest + 1
+		return nil, true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.WaitForIndex(ctx, next)
+}
+
+// WaitForBatchCovering waits until a submitted batch includes the given rollapp height and returns that batch.
+func (w *StateWatcher) WaitForBatchCovering(ctx context.Context, rollappHeight uint64) (*StateUpdate, error) {
+	return w.poll(ctx, fmt.Sprintf("a batch covering rollapp height %d", rollappHeight), func(ctx context.Context) (*StateUpdate, bool, error) {
+		latestIndex, err := w.LatestIndex(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+		latest, err := w.StateInfo(ctx, latestIndex)
+		if err != nil || latest.EndHeight() < rollappHeight {
+			return nil, false, err
+		}
+		if latest.Covers(rollappHeight) {
+			return latest, true, nil
+		}
+		update, err := w.StateInfoAtHeight(ctx, rollappHeight)
+		return update, err == nil, err
+	})
+}
+
+// WaitForStatus waits until the state update at index reaches the given status, e.g. FINALIZED, and returns it.
+func (w *StateWatcher) WaitForStatus(ctx context.Context, index uint64, status string) (*StateUpdate, error) {
+	return w.poll(ctx, fmt.Sprintf("state index %d to become %s", index, status), func(ctx context.Context) (*StateUpdate, bool, error) {
+		latest, err := w.LatestIndex(ctx)
+		if err != nil || latest < index {
+			return nil, false, err
+		}
+		update, err := w.StateInfo(ctx, index)
+		if err != nil {
+			return nil, false, err
+		}
+		return update, update.Status == status, nil
+	})
+}
+
+// poll runs check every stateWatcherPollInterval until it reports done or the deadline passes.
+// Errors returned by check are treated as transient and only reported as part of the timeout error.
+func (w *StateWatcher) poll(ctx context.Context, waitingFor string, check func(ctx context.Context) (*StateUpdate, bool, error)) (*StateUpdate, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultStateWatchTimeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(stateWatcherPollInterval)
+	defer ticker.Stop()
+
+	var lastErr error
+	for {
+		update, done, err := check(ctx)
+		if err != nil {
+			lastErr = err
+		} else if done {
+			return update, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, w.timeoutError(ctx, waitingFor, lastErr)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (w *StateWatcher) timeoutError(ctx context.Context, waitingFor string, lastErr error) error {
+	err := ctx.Err()
+	if lastErr != nil {
+		err = fmt.Errorf("%w (last query error: %v)", err, lastErr)
+	}
+	return &StateWatchTimeoutError{
+		RollappID:    w.rollappID,
+		WaitingFor:   waitingFor,
+		LastObserved: w.LastObserved(),
+		Err:          err,
+	}
+}
@@ -131,6 +131,8 @@ func TestBatchFinalization_EVM(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	walletAmount := math.NewInt(1_000_000_000_000)
 
 	// Create some user accounts on both chains
@@ -154,8 +156,19 @@ func TestBatchFinalization_EVM(t *testing.T) {
 	rollappHeight, err := rollapp1.GetNode().Height(ctx)
 	require.NoError(t, err)
 
-	IsAnyRollappStateFinalized(ctx, dymension, rollapp1.GetChainID(), 300)
+	// wait for a batch past the asserted height to finalize
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	finalizedBatch, err := stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight+1)
+	require.NoError(t, err)
+	require.Equal(t, stateStatusFinalized, finalizedBatch.Status)
+
+	// the hub finalizes the batch exactly when the dispute period has passed since its submission
+	finalizedAt, err := stateWatcher.FinalizationHubHeight(ctx, finalizedBatch.Index)
 	require.NoError(t, err)
+	require.Equal(t, finalizedBatch.CreationHeight+BLOCK_FINALITY_PERIOD, finalizedAt,
+		"state index %d created at hub height %d not finalized when the dispute period passed", finalizedBatch.Index, finalizedBatch.CreationHeight)
 
 	lastFinalizedRollappHeight, err := dymension.FinalizedRollappStateHeight(ctx, rollapp1.GetChainID())
 	require.NoError(t, err)
@@ -168,6 +181,13 @@ func TestBatchFinalization_EVM(t *testing.T) {
 	require.True(t, (currentFinalizedRollappDymHeight > BLOCK_FINALITY_PERIOD) && (lastFinalizedRollappHeight > rollappHeight),
 		fmt.Sprintf("Mismatch in batch finalization check. Current finalization hub height: %d. Dispute period: %d. Last finalized rollapp height: %d. Rollapp height asserted: %d",
 			currentFinalizedRollappDymHeight, BLOCK_FINALITY_PERIOD, lastFinalizedRollappHeight, rollappHeight))
+
+	// the block descriptors on the hub must reflect the blocks the rollapp produced
+	stateRootReport, err := VerifyStateRoots(ctx, dymension, rollapp1.CosmosChain, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, stateRootReport.Err())
+	require.Positive(t, stateRootReport.Verified)
+	t.Log(stateRootReport)
 }
 
 func TestBatchFinalization_Wasm(t *testing.T) {
@@ -273,6 +293,8 @@ func TestBatchFinalization_Wasm(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
 	walletAmount := math.NewInt(1_000_000_000_000)
 
 	// Create some user accounts on both chains
@@ -296,8 +318,19 @@ func TestBatchFinalization_Wasm(t *testing.T) {
 	rollappHeight, err := rollapp1.GetNode().Height(ctx)
 	require.NoError(t, err)
 
-	IsAnyRollappStateFinalized(ctx, dymension, rollapp1.GetChainID(), 300)
+	// wait for a batch past the asserted height to finalize
+	stateWatcher := NewStateWatcher(dymension, rollapp1.GetChainID())
+	finalizeCtx, cancel := context.WithTimeout(ctx, 300*time.Second)
+	defer cancel()
+	finalizedBatch, err := stateWatcher.WaitForHeightFinalized(finalizeCtx, rollappHeight+1)
 	require.NoError(t, err)
+	require.Equal(t, stateStatusFinalized, finalizedBatch.Status)
+
+	// the hub finalizes the batch exactly when the dispute period has passed since its submission
+	finalizedAt, err := stateWatcher.FinalizationHubHeight(ctx, finalizedBatch.Index)
+	require.NoError(t, err)
+	require.Equal(t, finalizedBatch.CreationHeight+BLOCK_FINALITY_PERIOD, finalizedAt,
+		"state index %d created at hub height %d not finalized when the dispute period passed", finalizedBatch.Index, finalizedBatch.CreationHeight)
 
 	lastFinalizedRollappHeight, err := dymension.FinalizedRollappStateHeight(ctx, rollapp1.GetChainID())
 	require.NoError(t, err)
@@ -310,32 +343,13 @@ func TestBatchFinalization_Wasm(t *testing.T) {
 	require.True(t, (currentFinalizedRollappDymHeight > BLOCK_FINALITY_PERIOD) && (lastFinalizedRollappHeight > rollappHeight),
 		fmt.Sprintf("Mismatch in batch finalization check. Current finalization hub height: %d. Dispute period: %d. Last finalized rollapp height: %d. Rollapp height asserted: %d",
 			currentFinalizedRollappDymHeight, BLOCK_FINALITY_PERIOD, lastFinalizedRollappHeight, rollappHeight))
-}
 
-func IsAnyRollappStateFinalized(ctx context.Context, dymension *dym_hub.DymHub, rollappChainID string, timeoutSecs int) (bool, error) {
-	var err error
-	startTime := time.Now()
-	timeout := time.Duration(timeoutSecs) * time.Second
-
-	for {
-		select {
-		case <-time.After(timeout):
-			return false, fmt.Errorf("timeout reached without rollap state finalization")
-		default:
-			var rollappState *dymensiontypes.RollappState
-			rollappState, err = dymension.QueryRollappState(ctx, rollappChainID, true)
-			if err != nil {
-				if time.Since(startTime) < timeout {
-					time.Sleep(2 * time.Second) // 2sec interval
-					continue
-				}
-			}
-
-			if rollappState.StateInfo.BlockDescriptors.BD != nil {
-				return true, nil
-			}
-		}
-	}
+	// the block descriptors on the hub must reflect the blocks the rollapp produced
+	stateRootReport, err := VerifyStateRoots(ctx, dymension, rollapp1.CosmosChain, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, stateRootReport.Err())
+	require.Positive(t, stateRootReport.Verified)
+	t.Log(stateRootReport)
 }
 
 func ValidateAndExtract(state dymensiontypes.RollappState) (*ExtractedInfo, error) {
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkerrors "github.com/cosmos/cosmos-sdk/types/errors"
+	"github.com/decentrio/rollup-e2e-testing/cosmos"
+	"github.com/stretchr/testify/require"
+)
+
+// TxResult returns the result of the transaction as executed in its block. Nodes check a transaction before
+// broadcasting it without running its messages, so the error of ExecTx misses the messages that fail.
+func TxResult(chain *cosmos.CosmosChain, txHash string) (*sdk.TxResponse, error) {
+	res, err := chain.GetTransaction(txHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tx %s on %s: %w", txHash, chain.Config().ChainID, err)
+	}
+	return res, nil
+}
+
+// KeyringNode returns the node holding the keys of the test users: the first full node of the chain, or its first
+// validator when it has no full node.
+func KeyringNode(chain *cosmos.CosmosChain) *cosmos.Node {
+	if len(chain.FullNodes) > 0 {
+		return chain.FullNodes[0]
+	}
+	return chain.Validators[0]
+}
+
+// TxFailedWith reports whether the executed transaction failed with the registered error.
+func TxFailedWith(res *sdk.TxResponse, expected *sdkerrors.Error) bool {
+	return res.Codespace == expected.Codespace() && res.Code == expected.ABCICode()
+}
+
+// RequireTxSucceeded asserts that the transaction succeeded when executed in its block, and returns its result.
+func RequireTxSucceeded(t *testing.T, chain *cosmos.CosmosChain, txHash string) *sdk.TxResponse {
+	res, err := TxResult(chain, txHash)
+	require.NoError(t, err)
+	require.Zero(t, res.Code, "tx %s failed: %s", txHash, res.RawLog)
+	return res
+}
+
+// RequireTxFailed asserts that the transaction failed with the registered error when executed in its block, and
+// returns its result.
+func RequireTxFailed(t *testing.T, chain *cosmos.CosmosChain, txHash string, expected *sdkerrors.Error) *sdk.TxResponse {
+	res, err := TxResult(chain, txHash)
+	require.NoError(t, err)
+	require.True(t, TxFailedWith(res, expected), "tx %s: want %s error %d (%s), got %s error %d: %s",
+		txHash, expected.Codespace(), expected.ABCICode(), expected, res.Codespace, res.Code, res.RawLog)
+	return res
+}
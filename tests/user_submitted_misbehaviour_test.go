@@ -0,0 +1,495 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"cosmossdk.io/math"
+	transfertypes "github.com/cosmos/ibc-go/v6/modules/apps/transfer/types"
+	test "github.com/decentrio/rollup-e2e-testing"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/hub/dym_hub"
+	"github.com/decentrio/rollup-e2e-testing/cosmos/rollapp/dym_rollapp"
+	"github.com/decentrio/rollup-e2e-testing/ibc"
+	"github.com/decentrio/rollup-e2e-testing/relayer"
+	"github.com/decentrio/rollup-e2e-testing/testreporter"
+	"github.com/decentrio/rollup-e2e-testing/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// rogueBlocks is how many blocks each sequencer builds past the fork before the misbehaviour is submitted
+const rogueBlocks = 5
+
+// This test case forks the rollapp by running a rogue sequencer with the key of the sequencer on a node cut off
+// from the rollapp. A user submits the headers of both sequencers at the same height to the hub as misbehaviour of
+// the client of the rollapp, and the hub freezes the client. The relayer does not detect nor submit the
+// misbehaviour here. The rollapp is not frozen on the hub, but no IBC packet goes through the client anymore
+func TestUserSubmittedMisbehaviour_EVM(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappevm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 1
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappevm_1234-1",
+				Images:              []ibc.DockerImage{rollappEVMImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "ethm",
+				Denom:               "urax",
+				CoinType:            "60",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       modifyRollappEVMGenesis(rollappEVMGenesisKV),
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	transferAmount := math.NewInt(1_000_000)
+
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+
+	hubTransfer := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	rollappTransfer := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+
+	// The channel works before the fork
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+
+	hubClientID, err := ChannelClientID(ctx, r, eRep, dymension.Config().ChainID, channel.Counterparty.ChannelID)
+	require.NoError(t, err)
+	rollappChannel, err := findChannel(ctx, r, eRep, rollapp1.Config().ChainID, channel.ChannelID)
+	require.NoError(t, err)
+
+	// The rogue sequencer runs on a new full node, the existing one keeps serving the honest rollapp
+	nodes, err := AddRollappFullNodes(ctx, rollapp1.CosmosChain, configFileOverrides, 1)
+	require.NoError(t, err)
+	rogue := nodes[0]
+
+	// Both headers of the misbehaviour are verified against a consensus state the client holds from before the fork
+	trustedHeight, err := QueryClientLatestHeight(ctx, dymension.CosmosChain, hubClientID)
+	require.NoError(t, err)
+
+	forkHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+	err = WaitForNodeHeight(ctx, rogue, forkHeight)
+	require.NoError(t, err)
+
+	faults := NewNetworkFaults(t, client, network)
+	err = StartRogueSequencer(ctx, faults, rollapp1.Validators[0], rogue, rollapp1.FullNodes[0])
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := StopNode(context.Background(), rogue); err != nil {
+			t.Logf("an error occurred while stopping the rogue sequencer: %s", err)
+		}
+	})
+
+	// Both sequencers build blocks past the fork, with the same key
+	err = WaitForNodeHeight(ctx, rogue, forkHeight+rogueBlocks)
+	require.NoError(t, err)
+	misbehaviourHeight, err := rogue.Height(ctx)
+	require.NoError(t, err)
+	err = WaitForNodeHeight(ctx, rollapp1.Validators[0], misbehaviourHeight)
+	require.NoError(t, err)
+
+	// A header at a new height is a regular update, the client is only frozen by two conflicting headers at the
+	// same height. Submit the headers of both sequencers at the same height as misbehaviour
+	status, err := QueryClientStatus(ctx, dymension.CosmosChain, hubClientID)
+	require.NoError(t, err)
+	require.Equal(t, clientStatusActive, status)
+
+	honestHeader, rogueHeader, err := ConflictingHeaders(ctx, rollapp1.Validators[0], rogue, int64(misbehaviourHeight), trustedHeight)
+	require.NoError(t, err)
+	err = SubmitMisbehaviourAsUser(ctx, dymension.CosmosChain, dymensionUser.KeyName(), hubClientID, honestHeader, rogueHeader)
+	require.NoError(t, err)
+
+	frozenCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	err = WaitForClientStatus(frozenCtx, dymension.CosmosChain, hubClientID, clientStatusFrozen)
+	require.NoError(t, err)
+
+	// Misbehaviour only freezes the light client, the rollapp itself is frozen by a fraud proposal
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.False(t, rollappParams.Rollapp.Frozen, "rollapp frozen by client misbehaviour")
+
+	// No packet goes through the frozen client: the hub refuses to send and the packets of the rollapp are never
+	// received
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.ErrorContains(t, err, clientStatusFrozen)
+
+	tx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, rollappTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+	TracePacketOnFailure(t, ctx, rollapp1.CosmosChain, tx.TxHash, dymension.CosmosChain)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	unrelayed, err := QueryUnrelayedPackets(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Contains(t, unrelayed, tx.Packet.Sequence)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+}
+
+func TestUserSubmittedMisbehaviour_Wasm(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	ctx := context.Background()
+
+	configFileOverrides := make(map[string]any)
+	dymintTomlOverrides := make(testutil.Toml)
+	dymintTomlOverrides["settlement_layer"] = "dymension"
+	dymintTomlOverrides["node_address"] = fmt.Sprintf("http://dymension_100-1-val-0-%s:26657", t.Name())
+	dymintTomlOverrides["rollapp_id"] = "rollappwasm_1234-1"
+	dymintTomlOverrides["gas_prices"] = "0adym"
+
+	configFileOverrides["config/dymint.toml"] = dymintTomlOverrides
+	// Create chain factory with dymension
+	numHubVals := 1
+	numHubFullNodes := 1
+	numRollAppFn := 1
+	numRollAppVals := 1
+	cf := test.NewBuiltinChainFactory(zaptest.NewLogger(t), []*test.ChainSpec{
+		{
+			Name: "rollapp1",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "rollapp-dym",
+				Name:                "rollapp-temp",
+				ChainID:             "rollappwasm_1234-1",
+				Images:              []ibc.DockerImage{rollappWasmImage},
+				Bin:                 "rollappd",
+				Bech32Prefix:        "rol",
+				Denom:               "urax",
+				CoinType:            "118",
+				GasPrices:           "0.0urax",
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				EncodingConfig:      encodingConfig(),
+				NoHostMount:         false,
+				ModifyGenesis:       nil,
+				ConfigFileOverrides: configFileOverrides,
+			},
+			NumValidators: &numRollAppVals,
+			NumFullNodes:  &numRollAppFn,
+		},
+		{
+			Name: "dymension-hub",
+			ChainConfig: ibc.ChainConfig{
+				Type:                "hub-dym",
+				Name:                "dymension",
+				ChainID:             "dymension_100-1",
+				Images:              []ibc.DockerImage{dymensionImage},
+				Bin:                 "dymd",
+				Bech32Prefix:        "dym",
+				Denom:               "adym",
+				CoinType:            "118",
+				GasPrices:           "0.0adym",
+				EncodingConfig:      encodingConfig(),
+				GasAdjustment:       1.1,
+				TrustingPeriod:      "112h",
+				NoHostMount:         false,
+				ModifyGenesis:       modifyDymensionGenesis(dymModifyGenesisKV),
+				ConfigFileOverrides: nil,
+			},
+			NumValidators: &numHubVals,
+			NumFullNodes:  &numHubFullNodes,
+		},
+	})
+
+	// Get chains from the chain factory
+	chains, err := cf.Chains(t.Name())
+	require.NoError(t, err)
+
+	rollapp1 := chains[0].(*dym_rollapp.DymRollApp)
+	dymension := chains[1].(*dym_hub.DymHub)
+
+	// Relayer Factory
+	client, network := test.DockerSetup(t)
+	r := NewRelayerFactory(zaptest.NewLogger(t),
+		relayer.CustomDockerImage("ghcr.io/decentrio/relayer", "e2e-amd", "100:1000"),
+	).Build(t, client, "relayer", network)
+	const ibcPath = "ibc-path"
+	ic := test.NewSetup().
+		AddRollUp(dymension, rollapp1).
+		AddRelayer(r, "relayer").
+		AddLink(test.InterchainLink{
+			Chain1:  dymension,
+			Chain2:  rollapp1,
+			Relayer: r,
+			Path:    ibcPath,
+		})
+
+	rep := testreporter.NewNopReporter()
+	eRep := rep.RelayerExecReporter(t)
+
+	err = ic.Build(ctx, eRep, test.InterchainBuildOptions{
+		TestName:         t.Name(),
+		Client:           client,
+		NetworkID:        network,
+		SkipPathCreation: false,
+
+		// This can be used to write to the block database which will index all block data e.g. txs, msgs, events, etc.
+		// BlockDatabaseFile: test.DefaultBlockDatabaseFilepath(),
+	})
+	require.NoError(t, err)
+
+	CheckBatchHistoryOnCleanup(t, ctx, dymension, rollapp1.GetChainID())
+
+	walletAmount := math.NewInt(1_000_000_000_000)
+
+	// Create some user accounts on both chains
+	users := test.GetAndFundTestUsers(t, ctx, t.Name(), walletAmount, dymension, rollapp1)
+
+	// Wait a few blocks for relayer to start and for user accounts to be created
+	err = testutil.WaitForBlocks(ctx, 5, dymension, rollapp1)
+	require.NoError(t, err)
+
+	// Get our Bech32 encoded user addresses
+	dymensionUser, rollappUser := users[0], users[1]
+
+	dymensionUserAddr := dymensionUser.FormattedAddress()
+	rollappUserAddr := rollappUser.FormattedAddress()
+
+	channel, err := ibc.GetTransferChannel(ctx, r, eRep, dymension.Config().ChainID, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+
+	err = r.StartRelayer(ctx, eRep, ibcPath)
+	require.NoError(t, err)
+
+	t.Cleanup(
+		func() {
+			err := r.StopRelayer(ctx, eRep)
+			if err != nil {
+				t.Logf("an error occurred while stopping the relayer: %s", err)
+			}
+		},
+	)
+
+	transferAmount := math.NewInt(1_000_000)
+
+	hubTokenDenom := transfertypes.GetPrefixedDenom(channel.PortID, channel.ChannelID, dymension.Config().Denom)
+	hubIBCDenom := transfertypes.ParseDenomTrace(hubTokenDenom).IBCDenom()
+
+	hubTransfer := ibc.WalletData{
+		Address: rollappUserAddr,
+		Denom:   dymension.Config().Denom,
+		Amount:  transferAmount,
+	}
+	rollappTransfer := ibc.WalletData{
+		Address: dymensionUserAddr,
+		Denom:   rollapp1.Config().Denom,
+		Amount:  transferAmount,
+	}
+
+	// The channel works before the fork
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+
+	hubClientID, err := ChannelClientID(ctx, r, eRep, dymension.Config().ChainID, channel.Counterparty.ChannelID)
+	require.NoError(t, err)
+	rollappChannel, err := findChannel(ctx, r, eRep, rollapp1.Config().ChainID, channel.ChannelID)
+	require.NoError(t, err)
+
+	// The rogue sequencer runs on a new full node, the existing one keeps serving the honest rollapp
+	nodes, err := AddRollappFullNodes(ctx, rollapp1.CosmosChain, configFileOverrides, 1)
+	require.NoError(t, err)
+	rogue := nodes[0]
+
+	// Both headers of the misbehaviour are verified against a consensus state the client holds from before the fork
+	trustedHeight, err := QueryClientLatestHeight(ctx, dymension.CosmosChain, hubClientID)
+	require.NoError(t, err)
+
+	forkHeight, err := rollapp1.Height(ctx)
+	require.NoError(t, err)
+	err = WaitForNodeHeight(ctx, rogue, forkHeight)
+	require.NoError(t, err)
+
+	faults := NewNetworkFaults(t, client, network)
+	err = StartRogueSequencer(ctx, faults, rollapp1.Validators[0], rogue, rollapp1.FullNodes[0])
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if err := StopNode(context.Background(), rogue); err != nil {
+			t.Logf("an error occurred while stopping the rogue sequencer: %s", err)
+		}
+	})
+
+	// Both sequencers build blocks past the fork, with the same key
+	err = WaitForNodeHeight(ctx, rogue, forkHeight+rogueBlocks)
+	require.NoError(t, err)
+	misbehaviourHeight, err := rogue.Height(ctx)
+	require.NoError(t, err)
+	err = WaitForNodeHeight(ctx, rollapp1.Validators[0], misbehaviourHeight)
+	require.NoError(t, err)
+
+	// A header at a new height is a regular update, the client is only frozen by two conflicting headers at the
+	// same height. Submit the headers of both sequencers at the same height as misbehaviour
+	status, err := QueryClientStatus(ctx, dymension.CosmosChain, hubClientID)
+	require.NoError(t, err)
+	require.Equal(t, clientStatusActive, status)
+
+	honestHeader, rogueHeader, err := ConflictingHeaders(ctx, rollapp1.Validators[0], rogue, int64(misbehaviourHeight), trustedHeight)
+	require.NoError(t, err)
+	err = SubmitMisbehaviourAsUser(ctx, dymension.CosmosChain, dymensionUser.KeyName(), hubClientID, honestHeader, rogueHeader)
+	require.NoError(t, err)
+
+	frozenCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	err = WaitForClientStatus(frozenCtx, dymension.CosmosChain, hubClientID, clientStatusFrozen)
+	require.NoError(t, err)
+
+	// Misbehaviour only freezes the light client, the rollapp itself is frozen by a fraud proposal
+	rollappParams, err := dymension.QueryRollappParams(ctx, rollapp1.Config().ChainID)
+	require.NoError(t, err)
+	require.False(t, rollappParams.Rollapp.Frozen, "rollapp frozen by client misbehaviour")
+
+	// No packet goes through the frozen client: the hub refuses to send and the packets of the rollapp are never
+	// received
+	_, err = dymension.SendIBCTransfer(ctx, channel.Counterparty.ChannelID, dymensionUserAddr, hubTransfer, ibc.TransferOptions{})
+	require.ErrorContains(t, err, clientStatusFrozen)
+
+	tx, err := rollapp1.SendIBCTransfer(ctx, channel.ChannelID, rollappUserAddr, rollappTransfer, ibc.TransferOptions{})
+	require.NoError(t, err)
+	TracePacketOnFailure(t, ctx, rollapp1.CosmosChain, tx.TxHash, dymension.CosmosChain)
+
+	err = testutil.WaitForBlocks(ctx, 10, dymension, rollapp1)
+	require.NoError(t, err)
+	unrelayed, err := QueryUnrelayedPackets(ctx, rollapp1.CosmosChain, dymension.CosmosChain, rollappChannel)
+	require.NoError(t, err)
+	require.Contains(t, unrelayed, tx.Packet.Sequence)
+	testutil.AssertBalance(t, ctx, rollapp1, rollappUserAddr, hubIBCDenom, transferAmount)
+}